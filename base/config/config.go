@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -30,6 +31,14 @@ var (
 
 type parserFunc func(v string) (interface{}, error)
 
+// defaultTypeParsers are checked before defaultBuiltInParsers for types whose kind is ambiguous,
+// e.g. time.Duration is an int64
+var defaultTypeParsers = map[reflect.Type]parserFunc{
+	reflect.TypeOf(time.Duration(0)): func(v string) (interface{}, error) {
+		return time.ParseDuration(v)
+	},
+}
+
 var defaultBuiltInParsers = map[reflect.Kind]parserFunc{
 	reflect.Bool: func(v string) (interface{}, error) {
 		return strconv.ParseBool(v)
@@ -141,7 +150,10 @@ func parse(ref reflect.Value, prefix []string) error {
 			return ErrValueRequired
 		}
 
-		parser, ok := defaultBuiltInParsers[refField.Kind()]
+		parser, ok := defaultTypeParsers[refTypeField.Type]
+		if !ok {
+			parser, ok = defaultBuiltInParsers[refField.Kind()]
+		}
 		if ok {
 			realVal, err := parser(val)
 			if err != nil {
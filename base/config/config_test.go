@@ -109,6 +109,11 @@ type parserFailConfig struct {
 	A bool `env:"a"`
 }
 
+type durationConfig struct {
+	A time.Duration `env:"a"`
+	B time.Duration `env:"b" default:"1h30m"`
+}
+
 func TestParse(t *testing.T) {
 	mf := new(mockFuncs)
 	mfs := new(mockFS)
@@ -203,6 +208,16 @@ func TestParse(t *testing.T) {
 			},
 			expErr: ErrInvalidType,
 		},
+		{
+			desc: "duration parse failed",
+			cfg:  &durationConfig{},
+			mockFunc: func() {
+				mf.On("osEnv").Return([]string{
+					"a=10",
+				}).Once()
+			},
+			expErr: ErrInvalidType,
+		},
 	}
 
 	for _, test := range tests {
@@ -221,3 +236,19 @@ func TestParse(t *testing.T) {
 		mfs.AssertExpectations(t)
 	}
 }
+
+func TestParseDuration(t *testing.T) {
+	mf := new(mockFuncs)
+	osEnv = mf.osEnv
+
+	envData = map[string]string{}
+	mf.On("osEnv").Return([]string{
+		"a=250ms",
+	}).Once()
+
+	cfg := &durationConfig{}
+	assert.NoError(t, Parse(cfg))
+	assert.Equal(t, &durationConfig{A: 250 * time.Millisecond, B: 90 * time.Minute}, cfg)
+
+	mf.AssertExpectations(t)
+}
@@ -0,0 +1,189 @@
+// Package lexorank generates keys which order items by comparing them as strings,
+// so that an item can be moved between two others by rewriting its own key only.
+package lexorank
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// digits are in byte order, keys never end with the first digit so that there is always a key before them
+	digits = "0123456789abcdefghijklmnopqrstuvwxyz"
+	// Width is the length keys are appended and prepended at, which leaves room for a billion of each
+	Width = 6
+	// maxSpreadWidth keeps the keys spread countable in uint64
+	maxSpreadWidth = 12
+)
+
+var (
+	ErrInvalidKey   = errors.New("invalid key")
+	ErrInvalidRange = errors.New("invalid range")
+	ErrTooManyKeys  = errors.New("too many keys")
+)
+
+// Between returns a key greater than a and less than b in byte order, an empty a means the start
+// and an empty b means the end. Keys appended to the end or prepended to the start stay at Width digits,
+// while keys inserted between close ones grow, which Spread shortens again.
+func Between(a, b string) (string, error) {
+	if err := validate(a); err != nil {
+		return "", err
+	}
+	if err := validate(b); err != nil {
+		return "", err
+	}
+	if b != "" && a >= b {
+		return "", ErrInvalidRange
+	}
+
+	if b == "" {
+		return after(a), nil
+	}
+	if a == "" {
+		if key, ok := before(b); ok {
+			return key, nil
+		}
+	}
+	return midpoint(a, b), nil
+}
+
+// Spread returns n keys in ascending order, which are spread evenly at the same length
+func Spread(n int) ([]string, error) {
+	// NOTE: keeps a gap of a few dozen keys between every two keys
+	width, span := Width, pow(len(digits), Width)
+	for span/uint64(n+1) < uint64(len(digits)) {
+		if width == maxSpreadWidth {
+			return nil, ErrTooManyKeys
+		}
+		width, span = width+1, span*uint64(len(digits))
+	}
+
+	step := span / uint64(n+1)
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = encode(uint64(i+1)*step, width)
+	}
+	return keys, nil
+}
+
+func validate(key string) error {
+	if key == "" {
+		return nil
+	}
+	for i := 0; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return ErrInvalidKey
+		}
+	}
+	if key[len(key)-1] == digits[0] {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+// after returns a key greater than a by incrementing the first Width digits of a
+func after(a string) string {
+	if a == "" {
+		return string(digits[len(digits)/2])
+	}
+
+	key := []byte(pad(a, Width)[:Width])
+	for i := len(key) - 1; i >= 0; i-- {
+		if d := strings.IndexByte(digits, key[i]); d < len(digits)-1 {
+			key[i] = digits[d+1]
+			return string(key[:i+1])
+		}
+	}
+	// NOTE: every digit is the last one, so the key goes after a at its length
+	return a + string(digits[1])
+}
+
+// before returns a key less than b by decrementing the first Width digits of b, ok is false if there is no room
+func before(b string) (string, bool) {
+	key := []byte(pad(b, Width)[:Width])
+	// NOTE: cutting b off leaves a key before it already
+	if len(b) <= Width {
+		i := len(key) - 1
+		for ; i >= 0 && key[i] == digits[0]; i-- {
+			key[i] = digits[len(digits)-1]
+		}
+		if i < 0 {
+			return "", false
+		}
+		key[i] = digits[strings.IndexByte(digits, key[i])-1]
+	}
+
+	trimmed := strings.TrimRight(string(key), digits[:1])
+	return trimmed, trimmed != ""
+}
+
+// midpoint returns a key between a and b, b is the end if it's empty
+func midpoint(a, b string) string {
+	if b != "" {
+		// NOTE: a is padded with the first digit, which is where a shorter key sits
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(tail(a, n), b[n:])
+		}
+	}
+
+	da := 0
+	if a != "" {
+		da = strings.IndexByte(digits, a[0])
+	}
+	db := len(digits)
+	if b != "" {
+		db = strings.IndexByte(digits, b[0])
+	}
+	if db-da > 1 {
+		return string(digits[(da+db+1)/2])
+	}
+	// NOTE: the first digits are consecutive, so the key starts with the one of a and goes after the rest of a
+	if len(b) > 1 {
+		return b[:1]
+	}
+	return string(digits[da]) + midpoint(tail(a, 1), "")
+}
+
+// pad appends the first digit to the key until it's n digits long
+func pad(key string, n int) string {
+	if len(key) >= n {
+		return key
+	}
+	return key + strings.Repeat(digits[:1], n-len(key))
+}
+
+// encode returns v as a key of width digits, with the trailing first digits trimmed
+func encode(v uint64, width int) string {
+	key := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		key[i] = digits[v%uint64(len(digits))]
+		v /= uint64(len(digits))
+	}
+	return strings.TrimRight(string(key), digits[:1])
+}
+
+func pow(base, exp int) uint64 {
+	v := uint64(1)
+	for i := 0; i < exp; i++ {
+		v *= uint64(base)
+	}
+	return v
+}
+
+func digitAt(key string, i int) byte {
+	if i < len(key) {
+		return key[i]
+	}
+	return digits[0]
+}
+
+func tail(key string, i int) string {
+	if i < len(key) {
+		return key[i:]
+	}
+	return ""
+}
@@ -0,0 +1,171 @@
+package lexorank
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		desc   string
+		a      string
+		b      string
+		exp    string
+		expErr error
+	}{
+		{
+			desc: "first key",
+			exp:  "i",
+		},
+		{
+			desc: "append",
+			a:    "i",
+			exp:  "i00001",
+		},
+		{
+			desc: "append with carry",
+			a:    "i0000z",
+			exp:  "i0001",
+		},
+		{
+			desc: "append after long key",
+			a:    "i00000123",
+			exp:  "i00001",
+		},
+		{
+			desc: "append after the last digits",
+			a:    "zzzzzz",
+			exp:  "zzzzzz1",
+		},
+		{
+			desc: "prepend",
+			b:    "i",
+			exp:  "hzzzzz",
+		},
+		{
+			desc: "prepend with borrow",
+			b:    "i0001",
+			exp:  "i0000z",
+		},
+		{
+			desc: "prepend before long key",
+			b:    "i00000123",
+			exp:  "i",
+		},
+		{
+			desc: "prepend before the smallest key",
+			b:    "000001",
+			exp:  "000000i",
+		},
+		{
+			desc: "between distant keys",
+			a:    "a",
+			b:    "z",
+			exp:  "n",
+		},
+		{
+			desc: "between consecutive keys",
+			a:    "a",
+			b:    "b",
+			exp:  "ai",
+		},
+		{
+			desc: "between key and its extension",
+			a:    "a",
+			b:    "a1",
+			exp:  "a0i",
+		},
+		{
+			desc: "between keys sharing prefix",
+			a:    "ab",
+			b:    "ad",
+			exp:  "ac",
+		},
+		{
+			desc:   "reversed range",
+			a:      "b",
+			b:      "a",
+			expErr: ErrInvalidRange,
+		},
+		{
+			desc:   "empty range",
+			a:      "a",
+			b:      "a",
+			expErr: ErrInvalidRange,
+		},
+		{
+			desc:   "key with trailing first digit",
+			a:      "a0",
+			expErr: ErrInvalidKey,
+		},
+		{
+			desc:   "key with invalid digit",
+			b:      "A",
+			expErr: ErrInvalidKey,
+		},
+	}
+
+	for _, test := range tests {
+		act, err := Between(test.a, test.b)
+		if test.expErr != nil {
+			assert.EqualError(t, err, test.expErr.Error(), test.desc)
+		} else {
+			assert.NoError(t, err, test.desc)
+			assert.Equal(t, test.exp, act, test.desc)
+		}
+	}
+}
+
+func TestBetweenRepeatedly(t *testing.T) {
+	// appending and prepending keep keys at the same length
+	last, first := "", ""
+	for i := 0; i < 100000; i++ {
+		next, err := Between(last, "")
+		assert.NoError(t, err)
+		assert.Greater(t, next, last)
+		last = next
+
+		prev, err := Between("", first)
+		assert.NoError(t, err)
+		if first != "" {
+			assert.Less(t, prev, first)
+		}
+		first = prev
+	}
+	assert.LessOrEqual(t, len(last), Width)
+	assert.LessOrEqual(t, len(first), Width)
+
+	// inserting right after the same key again and again never runs out of room
+	lo, hi := "a", "b"
+	for i := 0; i < 1000; i++ {
+		mid, err := Between(lo, hi)
+		assert.NoError(t, err)
+		assert.Greater(t, mid, lo)
+		assert.Less(t, mid, hi)
+		hi = mid
+	}
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{0, 1, 36, 1000000} {
+		keys, err := Spread(n)
+		assert.NoError(t, err)
+		assert.Len(t, keys, n)
+		for i, key := range keys {
+			assert.NoError(t, validate(key))
+			assert.LessOrEqual(t, len(key), Width)
+			if i > 0 {
+				assert.Greater(t, key, keys[i-1])
+			}
+		}
+	}
+
+	// keys spread leave room in between
+	keys, err := Spread(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "o"}, keys)
+	mid, err := Between(keys[0], keys[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "i", mid)
+}
@@ -0,0 +1,243 @@
+// Package rrule implements the subset of RFC 5545 recurrence rules which repeats tasks: FREQ of
+// DAILY, WEEKLY, MONTHLY or YEARLY with INTERVAL, COUNT, UNTIL, BYDAY of weekly rules and
+// BYMONTHDAY of monthly rules. Weeks start on Monday.
+package rrule
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxSkips bounds the periods skipped for lacking a valid date, e.g. February 30th never happens
+const maxSkips = 100
+
+var (
+	ErrInvalidRule     = errors.New("invalid rule")
+	ErrUnsupportedRule = errors.New("unsupported rule")
+
+	weekdays = map[string]time.Weekday{
+		"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+		"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+	}
+	untilLayouts = []string{"20060102T150405Z", "20060102T150405", "20060102"}
+)
+
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	// Count is the number of occurrences including the first one, 0 means unlimited
+	Count int
+	// Until is the last time an occurrence can happen, zero means unlimited
+	Until      time.Time
+	ByDay      []time.Weekday
+	ByMonthDay []int
+}
+
+// Parse parses the value of RRULE property, the "RRULE:" prefix is optional
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, ErrInvalidRule
+	}
+
+	r := &Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" || seen[name] {
+			return nil, ErrInvalidRule
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			if r.Freq != Daily && r.Freq != Weekly && r.Freq != Monthly && r.Freq != Yearly {
+				err = ErrUnsupportedRule
+			}
+		case "INTERVAL":
+			r.Interval, err = positive(value)
+		case "COUNT":
+			r.Count, err = positive(value)
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseByMonthDay(value)
+		case "WKST":
+			if value != "MO" {
+				err = ErrUnsupportedRule
+			}
+		default:
+			err = ErrUnsupportedRule
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Freq == "" || (r.Count > 0 && !r.Until.IsZero()) {
+		return nil, ErrInvalidRule
+	}
+	if (r.ByDay != nil && r.Freq != Weekly) || (r.ByMonthDay != nil && r.Freq != Monthly) {
+		return nil, ErrUnsupportedRule
+	}
+	return r, nil
+}
+
+func positive(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return 0, ErrInvalidRule
+	}
+	return n, nil
+}
+
+// parseUntil takes a date as the end of the day, times without zone are taken as UTC
+func parseUntil(s string) (time.Time, error) {
+	for _, layout := range untilLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		if len(s) == len("20060102") {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		return t, nil
+	}
+	return time.Time{}, ErrInvalidRule
+}
+
+// parseByDay returns the weekdays in the order of a week starting on Monday
+func parseByDay(s string) ([]time.Weekday, error) {
+	days := []time.Weekday{}
+	seen := map[time.Weekday]bool{}
+	for _, v := range strings.Split(s, ",") {
+		d, ok := weekdays[v]
+		if !ok {
+			// NOTE: ordinal weekdays like 1MO are only meaningful to monthly and yearly rules
+			return nil, ErrUnsupportedRule
+		}
+		if !seen[d] {
+			seen[d] = true
+			days = append(days, d)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return weekIndex(days[i]) < weekIndex(days[j]) })
+	return days, nil
+}
+
+func parseByMonthDay(s string) ([]int, error) {
+	days := []int{}
+	for _, v := range strings.Split(s, ",") {
+		d, err := strconv.Atoi(v)
+		if err != nil || d == 0 || d < -31 || d > 31 {
+			return nil, ErrInvalidRule
+		}
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+// Next returns the first occurrence after prev, which must be an occurrence itself as it's where the
+// series is anchored, false is returned if the series ends before it. COUNT is up to the caller since
+// it depends on how many occurrences there have been.
+func (r *Rule) Next(prev time.Time) (time.Time, bool) {
+	var next time.Time
+	switch r.Freq {
+	case Daily:
+		next = prev.AddDate(0, 0, r.Interval)
+	case Weekly:
+		next = r.nextWeekly(prev)
+	case Monthly:
+		next = r.nextMonthly(prev)
+	case Yearly:
+		next = r.nextYearly(prev)
+	}
+	if next.IsZero() || (!r.Until.IsZero() && next.After(r.Until)) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+func (r *Rule) nextWeekly(prev time.Time) time.Time {
+	if len(r.ByDay) == 0 {
+		return prev.AddDate(0, 0, 7*r.Interval)
+	}
+	weekStart := prev.AddDate(0, 0, -weekIndex(prev.Weekday()))
+	for _, weeks := range []int{0, r.Interval} {
+		for _, d := range r.ByDay {
+			t := weekStart.AddDate(0, 0, 7*weeks+weekIndex(d))
+			if t.After(prev) {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (r *Rule) nextMonthly(prev time.Time) time.Time {
+	days := r.ByMonthDay
+	if len(days) == 0 {
+		days = []int{prev.Day()}
+	}
+	for i := 0; i <= maxSkips; i++ {
+		y, m := prev.Year(), prev.Month()+time.Month(i*r.Interval)
+		dim := daysIn(y, m)
+		monthDays := []int{}
+		for _, d := range days {
+			if d < 0 {
+				d = dim + d + 1
+			}
+			if d >= 1 && d <= dim {
+				monthDays = append(monthDays, d)
+			}
+		}
+		sort.Ints(monthDays)
+		for _, d := range monthDays {
+			t := date(prev, y, m, d)
+			if t.After(prev) {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func (r *Rule) nextYearly(prev time.Time) time.Time {
+	for i := 1; i <= maxSkips; i++ {
+		y := prev.Year() + i*r.Interval
+		if prev.Day() <= daysIn(y, prev.Month()) {
+			return date(prev, y, prev.Month(), prev.Day())
+		}
+	}
+	return time.Time{}
+}
+
+// weekIndex is the index of the weekday in a week starting on Monday
+func weekIndex(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
+
+func daysIn(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// date returns the date at the time of day of t
+func date(t time.Time, y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
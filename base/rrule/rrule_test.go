@@ -0,0 +1,207 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		desc   string
+		s      string
+		exp    *Rule
+		expErr error
+	}{
+		{
+			desc: "daily",
+			s:    "FREQ=DAILY",
+			exp:  &Rule{Freq: Daily, Interval: 1},
+		},
+		{
+			desc: "weekly with prefix, interval and days",
+			s:    "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=FR,MO,FR",
+			exp:  &Rule{Freq: Weekly, Interval: 2, ByDay: []time.Weekday{time.Monday, time.Friday}},
+		},
+		{
+			desc: "monthly with days and count",
+			s:    "FREQ=MONTHLY;BYMONTHDAY=1,-1;COUNT=12",
+			exp:  &Rule{Freq: Monthly, Interval: 1, Count: 12, ByMonthDay: []int{1, -1}},
+		},
+		{
+			desc: "yearly until date",
+			s:    "FREQ=YEARLY;UNTIL=20301231",
+			exp:  &Rule{Freq: Yearly, Interval: 1, Until: time.Date(2030, 12, 31, 23, 59, 59, 999999999, time.UTC)},
+		},
+		{
+			desc: "until time",
+			s:    "FREQ=DAILY;UNTIL=20300101T090000Z",
+			exp:  &Rule{Freq: Daily, Interval: 1, Until: time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)},
+		},
+		{
+			desc:   "empty",
+			s:      "",
+			expErr: ErrInvalidRule,
+		},
+		{
+			desc:   "without frequency",
+			s:      "INTERVAL=2",
+			expErr: ErrInvalidRule,
+		},
+		{
+			desc:   "zero interval",
+			s:      "FREQ=DAILY;INTERVAL=0",
+			expErr: ErrInvalidRule,
+		},
+		{
+			desc:   "both count and until",
+			s:      "FREQ=DAILY;COUNT=2;UNTIL=20300101",
+			expErr: ErrInvalidRule,
+		},
+		{
+			desc:   "repeated part",
+			s:      "FREQ=DAILY;FREQ=WEEKLY",
+			expErr: ErrInvalidRule,
+		},
+		{
+			desc:   "invalid month day",
+			s:      "FREQ=MONTHLY;BYMONTHDAY=32",
+			expErr: ErrInvalidRule,
+		},
+		{
+			desc:   "hourly",
+			s:      "FREQ=HOURLY",
+			expErr: ErrUnsupportedRule,
+		},
+		{
+			desc:   "ordinal weekday",
+			s:      "FREQ=WEEKLY;BYDAY=1MO",
+			expErr: ErrUnsupportedRule,
+		},
+		{
+			desc:   "days of monthly rule",
+			s:      "FREQ=MONTHLY;BYDAY=MO",
+			expErr: ErrUnsupportedRule,
+		},
+		{
+			desc:   "unknown part",
+			s:      "FREQ=DAILY;BYHOUR=9",
+			expErr: ErrUnsupportedRule,
+		},
+	}
+
+	for _, test := range tests {
+		act, err := Parse(test.s)
+		if test.expErr != nil {
+			assert.EqualError(t, err, test.expErr.Error(), test.desc)
+		} else {
+			assert.NoError(t, err, test.desc)
+			assert.Equal(t, test.exp, act, test.desc)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// 2024-01-10 is a Wednesday
+	prev := time.Date(2024, 1, 10, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc  string
+		rule  string
+		prev  time.Time
+		exp   time.Time
+		expOK bool
+	}{
+		{
+			desc:  "every other day",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			prev:  prev,
+			exp:   time.Date(2024, 1, 12, 9, 30, 0, 0, time.UTC),
+			expOK: true,
+		},
+		{
+			desc:  "weekly on the same weekday",
+			rule:  "FREQ=WEEKLY",
+			prev:  prev,
+			exp:   time.Date(2024, 1, 17, 9, 30, 0, 0, time.UTC),
+			expOK: true,
+		},
+		{
+			desc:  "weekly on a later day of the week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE,FR",
+			prev:  prev,
+			exp:   time.Date(2024, 1, 12, 9, 30, 0, 0, time.UTC),
+			expOK: true,
+		},
+		{
+			desc:  "weekly on the first day of a later week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			prev:  prev,
+			exp:   time.Date(2024, 1, 22, 9, 30, 0, 0, time.UTC),
+			expOK: true,
+		},
+		{
+			desc:  "monthly on the same day",
+			rule:  "FREQ=MONTHLY",
+			prev:  prev,
+			exp:   time.Date(2024, 2, 10, 9, 30, 0, 0, time.UTC),
+			expOK: true,
+		},
+		{
+			desc:  "monthly skips months without the day",
+			rule:  "FREQ=MONTHLY",
+			prev:  time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC),
+			exp:   time.Date(2024, 3, 31, 9, 30, 0, 0, time.UTC),
+			expOK: true,
+		},
+		{
+			desc:  "monthly on the last day",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=-1",
+			prev:  time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC),
+			exp:   time.Date(2024, 2, 29, 9, 30, 0, 0, time.UTC),
+			expOK: true,
+		},
+		{
+			desc:  "monthly on a later day of the month",
+			rule:  "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1,15",
+			prev:  prev,
+			exp:   time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC),
+			expOK: true,
+		},
+		{
+			desc:  "monthly across years",
+			rule:  "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1,5",
+			prev:  time.Date(2024, 11, 5, 9, 30, 0, 0, time.UTC),
+			exp:   time.Date(2025, 2, 1, 9, 30, 0, 0, time.UTC),
+			expOK: true,
+		},
+		{
+			desc:  "yearly skips years without leap day",
+			rule:  "FREQ=YEARLY",
+			prev:  time.Date(2024, 2, 29, 9, 30, 0, 0, time.UTC),
+			exp:   time.Date(2028, 2, 29, 9, 30, 0, 0, time.UTC),
+			expOK: true,
+		},
+		{
+			desc:  "until the occurrence",
+			rule:  "FREQ=DAILY;UNTIL=20240111",
+			prev:  prev,
+			exp:   time.Date(2024, 1, 11, 9, 30, 0, 0, time.UTC),
+			expOK: true,
+		},
+		{
+			desc: "until before the occurrence",
+			rule: "FREQ=DAILY;UNTIL=20240111T090000Z",
+			prev: prev,
+		},
+	}
+
+	for _, test := range tests {
+		r, err := Parse(test.rule)
+		assert.NoError(t, err, test.desc)
+		act, ok := r.Next(test.prev)
+		assert.Equal(t, test.expOK, ok, test.desc)
+		assert.Equal(t, test.exp, act, test.desc)
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -16,13 +17,39 @@ import (
 
 const (
 	shutdownTimeout = 10 * time.Second
+	hookTimeout     = 10 * time.Second
 )
 
-func Serve(addr string, router *gin.Engine) error {
+type drainingKey struct{}
+
+// Draining returns a channel which is closed once the server starts shutting down. Shutdown waits for
+// running requests, so long-lived responses like streams should end on it. It is nil for contexts
+// which aren't of requests served by Serve.
+func Draining(ctx context.Context) <-chan struct{} {
+	draining, _ := ctx.Value(drainingKey{}).(<-chan struct{})
+	return draining
+}
+
+// ShutdownHook is run after the server stops serving, it should return before ctx is done
+type ShutdownHook func(ctx context.Context)
+
+// Serve serves router on addr until SIGINT or SIGTERM, then shuts the server down and runs the hooks in order.
+// The hooks are run even if the server fails or doesn't shut down in time, they have a deadline of their own.
+func Serve(addr string, router *gin.Engine, hooks ...ShutdownHook) error {
+	// NOTE: requests still running when shutdown times out are canceled through their context
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+	drainingCtx, drain := context.WithCancel(context.Background())
+	defer drain()
+	baseCtx = context.WithValue(baseCtx, drainingKey{}, drainingCtx.Done())
 	srv := http.Server{
 		Addr:    addr,
 		Handler: router,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
+	srv.RegisterOnShutdown(drain)
 
 	router.GET("/metrics", prometheusHandler())
 
@@ -37,17 +64,21 @@ func Serve(addr string, router *gin.Engine) error {
 	shutdownCh := make(chan os.Signal, 1)
 	signal.Notify(shutdownCh, syscall.SIGINT, syscall.SIGTERM)
 
+	var err error
 	select {
-	case err := <-srvCh:
-		return err
+	case err = <-srvCh:
 	case <-shutdownCh:
 		timeoutCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 		defer cancel()
-		if err := srv.Shutdown(timeoutCtx); err != nil {
-			return err
-		}
-		return nil
+		err = srv.Shutdown(timeoutCtx)
+	}
+
+	hookCtx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	for _, hook := range hooks {
+		hook(hookCtx)
 	}
+	return err
 }
 
 func prometheusHandler() gin.HandlerFunc {
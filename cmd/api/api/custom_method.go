@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+)
+
+// customMethods are the handlers of custom methods like "POST /tasks:batch", keyed by method and path.
+// The router takes ":" as the start of a wildcard, so they're served by CustomMethod instead.
+var customMethods = map[string]gin.HandlerFunc{}
+
+func handleCustomMethod(rg *gin.RouterGroup, method, relativePath string, handler gin.HandlerFunc) {
+	customMethods[method+" "+path.Join(rg.BasePath(), relativePath)] = handler
+}
+
+// CustomMethod serves the custom methods, the router must use it as the NoRoute handler
+func CustomMethod(c *gin.Context) {
+	handler, ok := customMethods[c.Request.Method+" "+c.Request.URL.Path]
+	if !ok {
+		// NOTE: the router responds 404 since nothing is written
+		return
+	}
+	c.Status(http.StatusOK)
+	handler(c)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	mw "github.com/chihkaiyu/task-todo-api/middlewares"
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/stores/tasks"
+)
+
+// @Summary List blockers
+// @Description Lists the tasks directly blocking the task
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param id path string true "task's ID"
+// @Success 200 {object} models.ListBlockerResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /task/{id}/blockers [get]
+func (th *taskHandler) listBlocker(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	task, err := th.taskStore.Get(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Get failed")
+		mw.Error(c, err)
+		return
+	}
+	if task.DeletedAt.Valid {
+		mw.Error(c, tasks.ErrTaskNotFound)
+		return
+	}
+
+	blockers, err := th.taskStore.ListBlockers(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.ListBlockers failed")
+		mw.Error(c, err)
+		return
+	}
+
+	dt := make([]*models.DisplayTask, len(blockers))
+	for i, t := range blockers {
+		dt[i] = t.Parse()
+	}
+
+	mw.JSON(c, http.StatusOK, models.ListBlockerResp{
+		Result: dt,
+	})
+}
+
+// @Summary Add blocker
+// @Description Marks the task as blocked by another task, the task can't be done until the blocker is done or archived
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param id path string true "task's ID"
+// @Param AddBlockerParams body models.AddBlockerParams true "the task blocking this task"
+// @Success 204
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 409 {object} models.BaseError "the blocker is blocked by the task already"
+// @Failure 410 {object} models.BaseError "the task is deleted"
+// @Failure 500 {object} models.BaseError
+// @Router /task/{id}/blockers [post]
+func (th *taskHandler) addBlocker(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	params := models.AddBlockerParams{}
+	if err := c.ShouldBindJSON(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindJSON failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	if err := th.taskStore.AddBlocker(ctx, id, params.BlockerID.String()); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.AddBlocker failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.NoContent(c)
+}
+
+// @Summary Remove blocker
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param id path string true "task's ID"
+// @Param blocker_id path string true "blocker's ID"
+// @Success 204
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /task/{id}/blockers/{blocker_id} [delete]
+func (th *taskHandler) removeBlocker(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	blockerID := c.Param("blocker_id")
+
+	if err := th.taskStore.RemoveBlocker(ctx, id, blockerID); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.RemoveBlocker failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.NoContent(c)
+}
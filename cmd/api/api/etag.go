@@ -0,0 +1,41 @@
+package api
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/stores/tasks"
+)
+
+var errInvalidIfMatch = models.BadRequestErr{Code: "INVALID_IF_MATCH"}
+
+// setETag sets the strong entity tag derived from the task's version
+func setETag(c *gin.Context, t *models.Task) {
+	c.Header("ETag", `"`+strconv.Itoa(t.Version)+`"`)
+}
+
+// ifMatch converts If-Match header to the preconditions of mutating a task.
+// Neither an absent header nor "*" adds any precondition.
+func ifMatch(c *gin.Context) ([]tasks.MutateTaskOptionFunc, error) {
+	h := strings.TrimSpace(c.GetHeader("If-Match"))
+	if h == "" || h == "*" {
+		return nil, nil
+	}
+	// NOTE: a task has exactly one current version, so a list of tags is meaningless
+	if strings.Contains(h, ",") {
+		return nil, errInvalidIfMatch
+	}
+
+	// NOTE: weak tags never match in If-Match since it requires strong comparison
+	if len(h) < 2 || h[0] != '"' || h[len(h)-1] != '"' {
+		return nil, tasks.ErrVersionMismatch
+	}
+	version, err := strconv.Atoi(h[1 : len(h)-1])
+	if err != nil {
+		return nil, tasks.ErrVersionMismatch
+	}
+	return []tasks.MutateTaskOptionFunc{tasks.WithVersion(version)}, nil
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/base/server"
+	mw "github.com/chihkaiyu/task-todo-api/middlewares"
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/services/postgres"
+	"github.com/chihkaiyu/task-todo-api/stores/events"
+)
+
+const (
+	// eventBatchSize is how many events are read at once
+	eventBatchSize = 100
+	// eventRetryInterval is how often held events are checked
+	eventRetryInterval = time.Second
+)
+
+var errInvalidLastEventID = models.BadRequestErr{Code: "INVALID_LAST_EVENT_ID"}
+
+type eventHandler struct {
+	eventStore events.Event
+	listener   *postgres.Listener
+	heartbeat  time.Duration
+	maxHold    time.Duration
+}
+
+// NewEventHandler streams task events, listener must listen to events.Channel. maxHold bounds how long events
+// are held for the transactions before them, see events.Cursor.
+func NewEventHandler(eventRG *gin.RouterGroup, eventStore events.Event, listener *postgres.Listener, heartbeat, maxHold time.Duration) {
+	eh := eventHandler{
+		eventStore: eventStore,
+		listener:   listener,
+		heartbeat:  heartbeat,
+		maxHold:    maxHold,
+	}
+
+	eventRG.GET("/tasks/events", eh.streamTaskEvent)
+}
+
+// @Summary Stream task events
+// @Description Streams task.created, task.updated and task.deleted events as server-sent events, whose data is the same as
+// @Description the body of webhooks. Events are emitted by mutations of tasks only, blocker, label and list changes emit none.
+// @Description Events are sent in the order they are committed, and an event is held until the
+// @Description transactions which may commit events before it end, for at most EVENTS_MAX_HOLD. Event ids are opaque cursors, the stream resumes after
+// @Description Last-Event-ID, otherwise it starts from now on and may include events committed shortly before.
+// @Description The stream sends a comment every heartbeat while idle, and ends when the server can't keep up with it,
+// @Description clients should reconnect with Last-Event-ID.
+// @Tags task
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "id of the last event received, events after it are sent first"
+// @Success 200 {object} models.WebhookEvent
+// @Failure 400 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /tasks/events [get]
+func (eh *eventHandler) streamTaskEvent(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	resume := false
+	var cursor events.Cursor
+	if h := c.GetHeader("Last-Event-ID"); h != "" {
+		var err error
+		if cursor, err = events.ParseCursor(h); err != nil {
+			mw.Error(c, errInvalidLastEventID)
+			return
+		}
+		resume = true
+	}
+
+	// NOTE: subscribe before reading the head, so that no event is committed in between unseen
+	payloads, unsubscribe := eh.listener.Subscribe()
+	defer unsubscribe()
+
+	if !resume {
+		var err error
+		if cursor, err = eh.eventStore.Head(ctx, eh.maxHold); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("eventStore.Head failed")
+			mw.Error(c, err)
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// NOTE: keeps nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	// NOTE: errors can't be responded once the stream starts, so the stream ends and the client resumes instead
+	pending, err := eh.sendTaskEvents(c, &cursor)
+	if err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(eh.heartbeat)
+	defer heartbeat.Stop()
+	// NOTE: held events are sent once the transactions before them end, which isn't notified, so they are polled
+	retry := time.NewTimer(eventRetryInterval)
+	defer retry.Stop()
+	if !pending {
+		retry.Stop()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-server.Draining(ctx):
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+			continue
+		case _, ok := <-payloads:
+			// NOTE: the subscription is closed if some notifications may be lost
+			if !ok {
+				return
+			}
+			// NOTE: notifications only wake the stream up, the events queued behind are read at once
+			drainNotifications(payloads)
+		case <-retry.C:
+		}
+
+		if pending, err = eh.sendTaskEvents(c, &cursor); err != nil {
+			return
+		}
+		if !retry.Stop() {
+			select {
+			case <-retry.C:
+			default:
+			}
+		}
+		if pending {
+			retry.Reset(eventRetryInterval)
+		}
+	}
+}
+
+// sendTaskEvents sends the events after the cursor and moves it, pending tells whether some events are held
+func (eh *eventHandler) sendTaskEvents(c *gin.Context, cursor *events.Cursor) (bool, error) {
+	ctx := c.Request.Context()
+	for {
+		batch, pending, err := eh.eventStore.List(ctx, *cursor, eh.maxHold, eventBatchSize)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("eventStore.List failed")
+			return false, err
+		}
+		for _, e := range batch {
+			if err := writeTaskEvent(c, e); err != nil {
+				return false, err
+			}
+			*cursor = events.CursorOf(e)
+		}
+		if pending || len(batch) < eventBatchSize {
+			return pending, nil
+		}
+	}
+}
+
+// drainNotifications drops the notifications queued
+func drainNotifications(payloads <-chan string) {
+	for {
+		select {
+		case _, ok := <-payloads:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func writeTaskEvent(c *gin.Context, e *models.TaskEvent) error {
+	data, err := json.Marshal(e.WebhookEvent())
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", events.CursorOf(e), e.Event, data); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
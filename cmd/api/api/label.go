@@ -0,0 +1,173 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	mw "github.com/chihkaiyu/task-todo-api/middlewares"
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/stores/labels"
+)
+
+type labelHandler struct {
+	labelStore labels.Label
+}
+
+func NewLabelHandler(labelRG *gin.RouterGroup, labelStore labels.Label) {
+	lh := labelHandler{
+		labelStore: labelStore,
+	}
+
+	labelRG.GET("/labels", lh.listLabel)
+	labelRG.GET("/label/:id", lh.getLabel)
+	labelRG.POST("/label", lh.createLabel)
+	labelRG.PUT("/label/:id", lh.putLabel)
+	labelRG.DELETE("/label/:id", lh.deleteLabel)
+}
+
+// @Summary List labels
+// @Tags label
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.ListLabelResp
+// @Failure 500 {object} models.BaseError
+// @Router /labels [get]
+func (lh *labelHandler) listLabel(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	labels, err := lh.labelStore.List(ctx)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("labelStore.List failed")
+		mw.Error(c, err)
+		return
+	}
+
+	dl := make([]*models.DisplayLabel, len(labels))
+	for i, l := range labels {
+		dl[i] = l.Parse()
+	}
+
+	mw.JSON(c, http.StatusOK, models.ListLabelResp{
+		Result: dl,
+	})
+}
+
+// @Summary Get label
+// @Tags label
+// @Accept json
+// @Produce json
+// @Param id path string true "label's ID"
+// @Success 200 {object} models.GetLabelResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /label/{id} [get]
+func (lh *labelHandler) getLabel(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	label, err := lh.labelStore.Get(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("labelStore.Get failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.JSON(c, http.StatusOK, models.GetLabelResp{
+		Result: label.Parse(),
+	})
+}
+
+// @Summary Create label
+// @Tags label
+// @Accept json
+// @Produce json
+// @Param CreateLabelParams body models.CreateLabelParams true "parameters for creating label"
+// @Success 201 {object} models.CreateLabelResp
+// @Failure 400 {object} models.BaseError
+// @Failure 409 {object} models.BaseError "the name is taken"
+// @Failure 500 {object} models.BaseError
+// @Router /label [post]
+func (lh *labelHandler) createLabel(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	params := models.CreateLabelParams{}
+	if err := c.ShouldBindJSON(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindJSON failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	label, err := lh.labelStore.Create(ctx, &params)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("labelStore.Create failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.JSON(c, http.StatusCreated, models.CreateLabelResp{
+		Result: label.Parse(),
+	})
+}
+
+// @Summary Put label
+// @Description Renames the label, tasks it's attached to see the new name
+// @Tags label
+// @Accept json
+// @Produce json
+// @Param id path string true "label's ID"
+// @Param PutLabelParams body models.PutLabelParams true "parameters for updating label"
+// @Success 200 {object} models.PutLabelResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 409 {object} models.BaseError "the name is taken"
+// @Failure 500 {object} models.BaseError
+// @Router /label/{id} [put]
+func (lh *labelHandler) putLabel(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	params := models.PutLabelParams{}
+	if err := c.ShouldBindJSON(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindJSON failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	label, err := lh.labelStore.Put(ctx, id, &params)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("labelStore.Put failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.JSON(c, http.StatusOK, models.PutLabelResp{
+		Result: label.Parse(),
+	})
+}
+
+// @Summary Delete label
+// @Description Deletes the label and detaches it from every task
+// @Tags label
+// @Accept json
+// @Produce json
+// @Param id path string true "label's ID"
+// @Success 204
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /label/{id} [delete]
+func (lh *labelHandler) deleteLabel(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	if err := lh.labelStore.Delete(ctx, id); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("labelStore.Delete failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.NoContent(c)
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	mw "github.com/chihkaiyu/task-todo-api/middlewares"
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/stores/lists"
+	"github.com/chihkaiyu/task-todo-api/stores/tasks"
+)
+
+type listHandler struct {
+	listStore lists.List
+	taskStore tasks.Task
+}
+
+func NewListHandler(listRG *gin.RouterGroup, listStore lists.List, taskStore tasks.Task) {
+	lh := listHandler{
+		listStore: listStore,
+		taskStore: taskStore,
+	}
+
+	listRG.GET("/lists", lh.listList)
+	listRG.GET("/list/:id", lh.getList)
+	listRG.GET("/lists/:id/tasks", lh.listListTask)
+	listRG.POST("/list", lh.createList)
+	listRG.PUT("/list/:id", lh.putList)
+	listRG.POST("/list/:id/archive", lh.archiveList)
+	listRG.POST("/list/:id/unarchive", lh.unarchiveList)
+	listRG.DELETE("/list/:id", lh.deleteList)
+}
+
+// @Summary List lists
+// @Tags list
+// @Accept json
+// @Produce json
+// @Param with_archived query bool false "include archived lists"
+// @Success 200 {object} models.ListListResp
+// @Failure 400 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /lists [get]
+func (lh *listHandler) listList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	params := models.ListListParams{}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindQuery failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	lists, err := lh.listStore.List(ctx, params.WithArchived)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("listStore.List failed")
+		mw.Error(c, err)
+		return
+	}
+
+	dl := make([]*models.DisplayList, len(lists))
+	for i, l := range lists {
+		dl[i] = l.Parse()
+	}
+
+	mw.JSON(c, http.StatusOK, models.ListListResp{
+		Result: dl,
+	})
+}
+
+// @Summary Get list
+// @Tags list
+// @Accept json
+// @Produce json
+// @Param id path string true "list's ID"
+// @Success 200 {object} models.GetListResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /list/{id} [get]
+func (lh *listHandler) getList(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	list, err := lh.listStore.Get(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("listStore.Get failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.JSON(c, http.StatusOK, models.GetListResp{
+		Result: list.Parse(),
+	})
+}
+
+// @Summary List tasks in list
+// @Description Lists the tasks in the list, accepts the same query as listing tasks
+// @Tags list
+// @Accept json
+// @Produce json
+// @Param id path string true "list's ID"
+// @Param limit query int false "page size, defaults to 20" minimum(0) maximum(100)
+// @Param cursor query string false "next_cursor returned by the previous page"
+// @Param status query string false "filter by status" Enums(todo, in_progress, done, archived)
+// @Param sort query string false "position, created_at, updated_at, name, due_at, priority or dependency, prefix with - for descending" default(position)
+// @Success 200 {object} models.ListTaskResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /lists/{id}/tasks [get]
+func (lh *listHandler) listListTask(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	params := models.ListTaskParams{}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindQuery failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	list, err := lh.listStore.Get(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("listStore.Get failed")
+		mw.Error(c, err)
+		return
+	}
+
+	opts := append(listOptions(&params), tasks.WithList(list.ID))
+	tasks, next, err := lh.taskStore.List(ctx, opts...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.List failed")
+		mw.Error(c, err)
+		return
+	}
+
+	dt := make([]*models.DisplayTask, len(tasks))
+	for i, t := range tasks {
+		dt[i] = t.Parse()
+	}
+
+	mw.JSON(c, http.StatusOK, models.ListTaskResp{
+		Result:     dt,
+		NextCursor: next,
+	})
+}
+
+// @Summary Create list
+// @Tags list
+// @Accept json
+// @Produce json
+// @Param CreateListParams body models.CreateListParams true "parameters for creating list"
+// @Success 201 {object} models.CreateListResp
+// @Failure 400 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /list [post]
+func (lh *listHandler) createList(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	params := models.CreateListParams{}
+	if err := c.ShouldBindJSON(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindJSON failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	list, err := lh.listStore.Create(ctx, &params)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("listStore.Create failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.JSON(c, http.StatusCreated, models.CreateListResp{
+		Result: list.Parse(),
+	})
+}
+
+// @Summary Put list
+// @Description Renames the list
+// @Tags list
+// @Accept json
+// @Produce json
+// @Param id path string true "list's ID"
+// @Param PutListParams body models.PutListParams true "parameters for updating list"
+// @Success 200 {object} models.PutListResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /list/{id} [put]
+func (lh *listHandler) putList(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	params := models.PutListParams{}
+	if err := c.ShouldBindJSON(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindJSON failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	list, err := lh.listStore.Put(ctx, id, &params)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("listStore.Put failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.JSON(c, http.StatusOK, models.PutListResp{
+		Result: list.Parse(),
+	})
+}
+
+// @Summary Archive list
+// @Description Archives the list, tasks in it are kept but no task can be added to it
+// @Tags list
+// @Accept json
+// @Produce json
+// @Param id path string true "list's ID"
+// @Success 200 {object} models.ArchiveListResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 409 {object} models.BaseError "the list is archived already"
+// @Failure 500 {object} models.BaseError
+// @Router /list/{id}/archive [post]
+func (lh *listHandler) archiveList(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	list, err := lh.listStore.Archive(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("listStore.Archive failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.JSON(c, http.StatusOK, models.ArchiveListResp{
+		Result: list.Parse(),
+	})
+}
+
+// @Summary Unarchive list
+// @Tags list
+// @Accept json
+// @Produce json
+// @Param id path string true "list's ID"
+// @Success 200 {object} models.ArchiveListResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 409 {object} models.BaseError "the list isn't archived"
+// @Failure 500 {object} models.BaseError
+// @Router /list/{id}/unarchive [post]
+func (lh *listHandler) unarchiveList(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	list, err := lh.listStore.Unarchive(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("listStore.Unarchive failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.JSON(c, http.StatusOK, models.ArchiveListResp{
+		Result: list.Parse(),
+	})
+}
+
+// @Summary Delete list
+// @Description Deletes the list, which must have no task but deleted ones. Deleted tasks are moved out of it.
+// @Tags list
+// @Accept json
+// @Produce json
+// @Param id path string true "list's ID"
+// @Success 204
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 409 {object} models.BaseError "the list has tasks"
+// @Failure 500 {object} models.BaseError
+// @Router /list/{id} [delete]
+func (lh *listHandler) deleteList(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	if err := lh.listStore.Delete(ctx, id); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("listStore.Delete failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.NoContent(c)
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+const (
+	mimeMergePatch = "application/merge-patch+json"
+	mimeJSONPatch  = "application/json-patch+json"
+)
+
+var (
+	errInvalidPatch     = models.BadRequestErr{Code: "INVALID_PATCH"}
+	errPatchTestFailed  = models.ConflictErr{Code: "PATCH_TEST_FAILED"}
+	errUnsupportedPatch = models.UnsupportedMediaTypeErr{Code: "UNSUPPORTED_PATCH_TYPE"}
+)
+
+// jsonPatchOp is an operation of RFC 6902 JSON Patch document
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// parseMergePatch converts RFC 7396 JSON Merge Patch document to PatchTaskParams.
+// null, which means removal, is rejected except for due_at, labels, parent_id, list_id and rrule since the other members are required.
+func parseMergePatch(body []byte) (*models.PatchTaskParams, error) {
+	doc := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, errInvalidPatch
+	}
+
+	params := &models.PatchTaskParams{}
+	for k, v := range doc {
+		if err := setPatchField(params, k, v); err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
+// parseJSONPatch converts RFC 6902 JSON Patch document to PatchTaskParams.
+// Only add, replace, remove and test are supported, only due_at, labels, parent_id, list_id and rrule can be removed.
+// test operations are evaluated against current.
+func parseJSONPatch(body []byte, current *models.Task) (*models.PatchTaskParams, error) {
+	ops := []*jsonPatchOp{}
+	if err := json.Unmarshal(body, &ops); err != nil {
+		return nil, errInvalidPatch
+	}
+
+	params := &models.PatchTaskParams{}
+	for _, op := range ops {
+		if !strings.HasPrefix(op.Path, "/") {
+			return nil, errInvalidPatch
+		}
+		field := op.Path[1:]
+
+		switch op.Op {
+		case "add", "replace":
+			if len(op.Value) == 0 {
+				return nil, errInvalidPatch
+			}
+			if err := setPatchField(params, field, op.Value); err != nil {
+				return nil, err
+			}
+		case "remove":
+			if err := setPatchField(params, field, json.RawMessage("null")); err != nil {
+				return nil, err
+			}
+		case "test":
+			expected := &models.PatchTaskParams{}
+			if len(op.Value) == 0 {
+				return nil, errInvalidPatch
+			}
+			if err := setPatchField(expected, field, op.Value); err != nil {
+				return nil, err
+			}
+			// NOTE: test sees the result of the previous operations in the same document
+			if !patchTestPass(expected, params, current) {
+				return nil, errPatchTestFailed
+			}
+		default:
+			return nil, errInvalidPatch
+		}
+	}
+	return params, nil
+}
+
+func setPatchField(params *models.PatchTaskParams, field string, value json.RawMessage) error {
+	if string(value) == "null" {
+		switch field {
+		case "due_at":
+			params.DueAt = nil
+			params.ClearDueAt = true
+		case "labels":
+			params.Labels = []string{}
+		case "parent_id":
+			params.ParentID = nil
+			params.ClearParentID = true
+		case "list_id":
+			params.ListID = nil
+			params.ClearListID = true
+		case "rrule":
+			params.RRule = nil
+			params.ClearRRule = true
+		default:
+			return errInvalidPatch
+		}
+		return nil
+	}
+
+	switch field {
+	case "name":
+		name := ""
+		if err := json.Unmarshal(value, &name); err != nil {
+			return errInvalidPatch
+		}
+		params.Name = &name
+	case "status":
+		var status models.TaskStatus
+		if err := json.Unmarshal(value, &status); err != nil {
+			return errInvalidPatch
+		}
+		params.Status = &status
+	case "description":
+		description := ""
+		if err := json.Unmarshal(value, &description); err != nil {
+			return errInvalidPatch
+		}
+		params.Description = &description
+	case "priority":
+		priority := 0
+		if err := json.Unmarshal(value, &priority); err != nil {
+			return errInvalidPatch
+		}
+		params.Priority = &priority
+	case "due_at":
+		dueAt := time.Time{}
+		if err := json.Unmarshal(value, &dueAt); err != nil {
+			return errInvalidPatch
+		}
+		params.DueAt = &dueAt
+		params.ClearDueAt = false
+	case "labels":
+		labels := []string{}
+		if err := json.Unmarshal(value, &labels); err != nil || labels == nil {
+			return errInvalidPatch
+		}
+		params.Labels = labels
+	case "parent_id":
+		parentID := uuid.UUID{}
+		if err := json.Unmarshal(value, &parentID); err != nil {
+			return errInvalidPatch
+		}
+		params.ParentID = &parentID
+		params.ClearParentID = false
+	case "list_id":
+		listID := uuid.UUID{}
+		if err := json.Unmarshal(value, &listID); err != nil {
+			return errInvalidPatch
+		}
+		params.ListID = &listID
+		params.ClearListID = false
+	case "rrule":
+		rule := ""
+		if err := json.Unmarshal(value, &rule); err != nil {
+			return errInvalidPatch
+		}
+		params.RRule = &rule
+		params.ClearRRule = false
+	default:
+		return errInvalidPatch
+	}
+	return nil
+}
+
+func patchTestPass(expected, patched *models.PatchTaskParams, current *models.Task) bool {
+	switch {
+	case expected.Name != nil:
+		name := current.Name
+		if patched.Name != nil {
+			name = *patched.Name
+		}
+		return *expected.Name == name
+	case expected.Description != nil:
+		description := current.Description
+		if patched.Description != nil {
+			description = *patched.Description
+		}
+		return *expected.Description == description
+	case expected.Status != nil:
+		status := current.Status
+		if patched.Status != nil {
+			status = *patched.Status
+		}
+		return *expected.Status == status
+	case expected.Priority != nil:
+		priority := current.Priority
+		if patched.Priority != nil {
+			priority = *patched.Priority
+		}
+		return *expected.Priority == priority
+	case expected.DueAt != nil || expected.ClearDueAt:
+		dueAt := current.DueAt
+		if patched.DueAt != nil || patched.ClearDueAt {
+			dueAt = models.NullTime(patched.DueAt)
+		}
+		if expected.ClearDueAt {
+			return !dueAt.Valid
+		}
+		return dueAt.Valid && expected.DueAt.Equal(dueAt.Time)
+	case expected.Labels != nil:
+		labels := []string(current.Labels)
+		if patched.Labels != nil {
+			labels = patched.Labels
+		}
+		return sameStrings(expected.Labels, labels)
+	case expected.ParentID != nil || expected.ClearParentID:
+		parentID := current.ParentID
+		if patched.ParentID != nil || patched.ClearParentID {
+			parentID = models.NullUUID(patched.ParentID)
+		}
+		if expected.ClearParentID {
+			return !parentID.Valid
+		}
+		return parentID.Valid && *expected.ParentID == parentID.UUID
+	case expected.ListID != nil || expected.ClearListID:
+		listID := current.ListID
+		if patched.ListID != nil || patched.ClearListID {
+			listID = models.NullUUID(patched.ListID)
+		}
+		if expected.ClearListID {
+			return !listID.Valid
+		}
+		return listID.Valid && *expected.ListID == listID.UUID
+	case expected.RRule != nil || expected.ClearRRule:
+		rule := current.RRule.String
+		if patched.RRule != nil || patched.ClearRRule {
+			rule = ""
+			if patched.RRule != nil {
+				rule = *patched.RRule
+			}
+		}
+		if expected.ClearRRule {
+			return rule == ""
+		}
+		return *expected.RRule == rule
+	}
+	return false
+}
+
+// sameStrings tells whether a and b have the same elements regardless of order and duplicates
+func sameStrings(a, b []string) bool {
+	setA, setB := map[string]bool{}, map[string]bool{}
+	for _, s := range a {
+		setA[s] = true
+	}
+	for _, s := range b {
+		setB[s] = true
+	}
+	if len(setA) != len(setB) {
+		return false
+	}
+	for s := range setA {
+		if !setB[s] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	mw "github.com/chihkaiyu/task-todo-api/middlewares"
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/stores/reminders"
+)
+
+type reminderHandler struct {
+	reminderStore reminders.Reminder
+}
+
+func NewReminderHandler(reminderRG *gin.RouterGroup, reminderStore reminders.Reminder) {
+	rh := reminderHandler{
+		reminderStore: reminderStore,
+	}
+
+	reminderRG.GET("/task/:id/reminders", rh.listReminder)
+	reminderRG.POST("/task/:id/reminders", rh.createReminder)
+	reminderRG.DELETE("/task/:id/reminders/:reminder_id", rh.deleteReminder)
+}
+
+// @Summary List reminders
+// @Description Lists the reminders of the task in the order they fire
+// @Tags reminder
+// @Accept json
+// @Produce json
+// @Param id path string true "task's ID"
+// @Success 200 {object} models.ListReminderResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /task/{id}/reminders [get]
+func (rh *reminderHandler) listReminder(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	reminders, err := rh.reminderStore.List(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("reminderStore.List failed")
+		mw.Error(c, err)
+		return
+	}
+
+	dr := make([]*models.DisplayReminder, len(reminders))
+	for i, r := range reminders {
+		dr[i] = r.Parse()
+	}
+
+	mw.JSON(c, http.StatusOK, models.ListReminderResp{
+		Result: dr,
+	})
+}
+
+// @Summary Create reminder
+// @Description Adds a reminder at remind_at, or offset_minutes after the due date of the task. It's sent to the
+// @Description reminder webhook URLs as a task.reminder event unless the task is done or archived by then.
+// @Tags reminder
+// @Accept json
+// @Produce json
+// @Param id path string true "task's ID"
+// @Param CreateReminderParams body models.CreateReminderParams true "when the reminder fires"
+// @Success 201 {object} models.CreateReminderResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /task/{id}/reminders [post]
+func (rh *reminderHandler) createReminder(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	params := models.CreateReminderParams{}
+	if err := c.ShouldBindJSON(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindJSON failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	reminder, err := rh.reminderStore.Create(ctx, id, &params)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("reminderStore.Create failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.JSON(c, http.StatusCreated, models.CreateReminderResp{
+		Result: reminder.Parse(),
+	})
+}
+
+// @Summary Delete reminder
+// @Tags reminder
+// @Accept json
+// @Produce json
+// @Param id path string true "task's ID"
+// @Param reminder_id path string true "reminder's ID"
+// @Success 204
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /task/{id}/reminders/{reminder_id} [delete]
+func (rh *reminderHandler) deleteReminder(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	reminderID := c.Param("reminder_id")
+
+	if err := rh.reminderStore.Delete(ctx, id, reminderID); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("reminderStore.Delete failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.NoContent(c)
+}
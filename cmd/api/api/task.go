@@ -2,8 +2,10 @@ package api
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/rs/zerolog"
 
 	mw "github.com/chihkaiyu/task-todo-api/middlewares"
@@ -21,15 +23,36 @@ func NewTaskHandler(taskRG *gin.RouterGroup, taskStore tasks.Task) {
 	}
 
 	taskRG.GET("/tasks", th.listTask)
+	taskRG.GET("/task/:id", th.getTask)
 	taskRG.POST("/task", th.createTask)
 	taskRG.PUT("task/:id", th.putTask)
+	taskRG.PATCH("/task/:id", th.patchTask)
 	taskRG.DELETE("/task/:id", th.deleteTask)
+	taskRG.POST("/task/:id/restore", th.restoreTask)
+	taskRG.POST("/task/:id/move", th.moveTask)
+	taskRG.GET("/task/:id/subtasks", th.listSubtask)
+	taskRG.GET("/task/:id/blockers", th.listBlocker)
+	taskRG.POST("/task/:id/blockers", th.addBlocker)
+	taskRG.DELETE("/task/:id/blockers/:blocker_id", th.removeBlocker)
+	handleCustomMethod(taskRG, http.MethodPost, "/tasks:batch", th.batchTask)
 }
 
 // @Summary List tasks
 // @Tags task
 // @Accept json
 // @Produce json
+// @Param limit query int false "page size, defaults to 20" minimum(0) maximum(100)
+// @Param cursor query string false "next_cursor returned by the previous page"
+// @Param status query string false "filter by status, the legacy 0 and 1 are accepted as todo and done" Enums(todo, in_progress, done, archived)
+// @Param name query string false "filter by case-insensitive substring of name"
+// @Param q query string false "full-text search, every word matches as a prefix" maxlength(200)
+// @Param label query string false "filter by comma-separated names of labels"
+// @Param label_match query string false "list tasks having any or all of the labels" Enums(any, all) default(any)
+// @Param priority query int false "filter by priority" minimum(0) maximum(3)
+// @Param due_after query string false "filter tasks due at or after the time in RFC 3339" format(date-time)
+// @Param due_before query string false "filter tasks due before the time in RFC 3339" format(date-time)
+// @Param sort query string false "position, created_at, updated_at, name, due_at, priority, dependency or rank, prefix with - for descending, defaults to -rank with q and position otherwise"
+// @Param order query string false "dependency lists every task after the tasks blocking it, same as sort=dependency" Enums(dependency)
 // @Success 200 {object} models.ListTaskResp
 // @Failure 400 {object} models.BaseError
 // @Failure 500 {object} models.BaseError
@@ -37,7 +60,14 @@ func NewTaskHandler(taskRG *gin.RouterGroup, taskStore tasks.Task) {
 func (th *taskHandler) listTask(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	tasks, err := th.taskStore.List(ctx)
+	params := models.ListTaskParams{}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindQuery failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	tasks, next, err := th.taskStore.List(ctx, listOptions(&params)...)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.List failed")
 		mw.Error(c, err)
@@ -50,7 +80,145 @@ func (th *taskHandler) listTask(c *gin.Context) {
 	}
 
 	mw.JSON(c, http.StatusOK, models.ListTaskResp{
-		Result: dt,
+		Result:     dt,
+		NextCursor: next,
+	})
+}
+
+// listOptions converts the query of listing tasks to the options of taskStore.List
+func listOptions(params *models.ListTaskParams) []tasks.ListTaskOptionFunc {
+	opts := []tasks.ListTaskOptionFunc{
+		tasks.WithLimit(params.Limit),
+		tasks.WithCursor(params.Cursor),
+		tasks.WithNameContains(params.Name),
+		tasks.WithSearch(params.Q),
+		tasks.WithSort(params.Sort),
+	}
+	if params.Order == "dependency" {
+		opts = append(opts, tasks.WithSort("dependency"))
+	}
+	if params.Status != "" {
+		opts = append(opts, tasks.WithStatus(params.Status.Normalize()))
+	}
+	if params.Priority != nil {
+		opts = append(opts, tasks.WithPriority(*params.Priority))
+	}
+	if labels := splitLabels(params.Label); len(labels) > 0 {
+		opts = append(opts, tasks.WithLabels(labels, params.LabelMatch == "all"))
+	}
+	if !params.DueAfter.IsZero() {
+		opts = append(opts, tasks.WithDueAfter(params.DueAfter))
+	}
+	if !params.DueBefore.IsZero() {
+		opts = append(opts, tasks.WithDueBefore(params.DueBefore))
+	}
+	return opts
+}
+
+// splitLabels splits comma-separated names of labels, blank names are dropped
+func splitLabels(s string) []string {
+	labels := []string{}
+	for _, l := range strings.Split(s, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+// @Summary List subtasks
+// @Description Lists the direct subtasks of the task, accepts the same query as listing tasks
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param id path string true "task's ID"
+// @Param limit query int false "page size, defaults to 20" minimum(0) maximum(100)
+// @Param cursor query string false "next_cursor returned by the previous page"
+// @Param status query string false "filter by status" Enums(todo, in_progress, done, archived)
+// @Param sort query string false "position, created_at, updated_at, name, due_at, priority or dependency, prefix with - for descending" default(position)
+// @Success 200 {object} models.ListTaskResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /task/{id}/subtasks [get]
+func (th *taskHandler) listSubtask(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	params := models.ListTaskParams{}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindQuery failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	parent, err := th.taskStore.Get(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Get failed")
+		mw.Error(c, err)
+		return
+	}
+	if parent.DeletedAt.Valid {
+		mw.Error(c, tasks.ErrTaskNotFound)
+		return
+	}
+
+	opts := append(listOptions(&params), tasks.WithParent(parent.ID))
+	tasks, next, err := th.taskStore.List(ctx, opts...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.List failed")
+		mw.Error(c, err)
+		return
+	}
+
+	dt := make([]*models.DisplayTask, len(tasks))
+	for i, t := range tasks {
+		dt[i] = t.Parse()
+	}
+
+	mw.JSON(c, http.StatusOK, models.ListTaskResp{
+		Result:     dt,
+		NextCursor: next,
+	})
+}
+
+// @Summary Get task
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param id path string true "task's ID"
+// @Param with_deleted query bool false "return the task even if it is deleted"
+// @Success 200 {object} models.GetTaskResp
+// @Header 200 {string} ETag "version of the task"
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /task/{id} [get]
+func (th *taskHandler) getTask(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	params := models.GetTaskParams{}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindQuery failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	task, err := th.taskStore.Get(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Get failed")
+		mw.Error(c, err)
+		return
+	}
+	if task.DeletedAt.Valid && !params.WithDeleted {
+		mw.Error(c, tasks.ErrTaskNotFound)
+		return
+	}
+
+	setETag(c, task)
+	mw.JSON(c, http.StatusOK, models.GetTaskResp{
+		Result: task.Parse(),
 	})
 }
 
@@ -60,6 +228,7 @@ func (th *taskHandler) listTask(c *gin.Context) {
 // @Produce json
 // @Param CreateTaskParams body models.CreateTaskParams true "parameters for creating task"
 // @Success 200 {object} models.CreateTaskResp
+// @Header 200 {string} ETag "version of the task"
 // @Failure 400 {object} models.BaseError
 // @Failure 500 {object} models.BaseError
 // @Router /task [post]
@@ -69,30 +238,39 @@ func (th *taskHandler) createTask(c *gin.Context) {
 	params := models.CreateTaskParams{}
 	if err := c.ShouldBindJSON(&params); err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindJSON failed")
-		mw.Error(c, err)
+		mw.Error(c, mw.BindingError(err))
 		return
 	}
 
-	task, err := th.taskStore.Create(ctx, params.Name)
+	task, err := th.taskStore.Create(ctx, &params)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Create failed")
 		mw.Error(c, err)
 		return
 	}
 
+	setETag(c, task)
 	mw.JSON(c, http.StatusCreated, models.CreateTaskResp{
 		Result: task.Parse(),
 	})
 }
 
 // @Summary Put task
+// @Description Replaces the task as a whole. Omitted fields are reset: description, labels, due_at, parent_id, list_id and rrule are cleared,
+// @Description priority becomes 0 and status todo. Use PATCH to update only some fields.
 // @Tags task
 // @Accept json
 // @Produce json
 // @Param id path string true "task's ID"
+// @Param If-Match header string false "ETag of the task, the update is rejected if it's stale"
 // @Param PutTaskParams body models.PutTaskParams true "parameters for updating task"
 // @Success 200 {object} models.PutTaskResp
+// @Header 200 {string} ETag "version of the task"
 // @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 409 {object} models.BaseError
+// @Failure 410 {object} models.BaseError "the task is deleted"
+// @Failure 412 {object} models.BaseError
 // @Failure 500 {object} models.BaseError
 // @Router /task/{id} [put]
 func (th *taskHandler) putTask(c *gin.Context) {
@@ -102,40 +280,296 @@ func (th *taskHandler) putTask(c *gin.Context) {
 	params := models.PutTaskParams{}
 	if err := c.ShouldBindJSON(&params); err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindJSON failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	opts, err := ifMatch(c)
+	if err != nil {
 		mw.Error(c, err)
 		return
 	}
 
-	task, err := th.taskStore.Put(ctx, id, &params)
+	task, err := th.taskStore.Put(ctx, id, &params, opts...)
 	if err != nil {
 		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Put failed")
 		mw.Error(c, err)
 		return
 	}
 
+	setETag(c, task)
 	mw.JSON(c, http.StatusOK, models.PutTaskResp{
 		Result: task.Parse(),
 	})
 }
 
+// @Summary Patch task
+// @Description Updates only the supplied fields, accepts JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) documents
+// @Tags task
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path string true "task's ID"
+// @Param If-Match header string false "ETag of the task, the update is rejected if it's stale"
+// @Param PatchTaskParams body models.PatchTaskParams true "merge patch document, or an array of JSON Patch operations"
+// @Success 200 {object} models.PatchTaskResp
+// @Header 200 {string} ETag "version of the task"
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 409 {object} models.BaseError
+// @Failure 410 {object} models.BaseError "the task is deleted"
+// @Failure 412 {object} models.BaseError
+// @Failure 415 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /task/{id} [patch]
+func (th *taskHandler) patchTask(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	opts, err := ifMatch(c)
+	if err != nil {
+		mw.Error(c, err)
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.GetRawData failed")
+		mw.Error(c, err)
+		return
+	}
+
+	var params *models.PatchTaskParams
+	switch c.ContentType() {
+	case mimeMergePatch, gin.MIMEJSON:
+		params, err = parseMergePatch(body)
+	case mimeJSONPatch:
+		current, getErr := th.taskStore.Get(ctx, id)
+		if getErr != nil {
+			zerolog.Ctx(ctx).Error().Err(getErr).Msg("taskStore.Get failed")
+			mw.Error(c, getErr)
+			return
+		}
+		params, err = parseJSONPatch(body, current)
+		// NOTE: test operations were evaluated against current, make sure nothing changed since then
+		if len(opts) == 0 {
+			opts = append(opts, tasks.WithVersion(current.Version))
+		}
+	default:
+		err = errUnsupportedPatch
+	}
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("parse patch failed")
+		mw.Error(c, err)
+		return
+	}
+	if err := binding.Validator.ValidateStruct(params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("binding.Validator.ValidateStruct failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	task, err := th.taskStore.Patch(ctx, id, params, opts...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Patch failed")
+		mw.Error(c, err)
+		return
+	}
+
+	setETag(c, task)
+	mw.JSON(c, http.StatusOK, models.PatchTaskResp{
+		Result: task.Parse(),
+	})
+}
+
 // @Summary Delete task
+// @Description Soft-deletes the task, or removes it permanently with hard=true
 // @Tags task
 // @Accept json
 // @Produce json
 // @Param id path string true "task's ID"
-// @Success 200 {object} string
+// @Param hard query bool false "remove the task permanently"
+// @Param If-Match header string false "ETag of the task, the deletion is rejected if it's stale"
+// @Success 204
 // @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 410 {object} models.BaseError "the task is already deleted"
+// @Failure 412 {object} models.BaseError
 // @Failure 500 {object} models.BaseError
 // @Router /task/{id} [delete]
 func (th *taskHandler) deleteTask(c *gin.Context) {
 	ctx := c.Request.Context()
 	id := c.Param("id")
 
-	if err := th.taskStore.Delete(ctx, id); err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Delete failed")
+	params := models.DeleteTaskParams{}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindQuery failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	opts, err := ifMatch(c)
+	if err != nil {
+		mw.Error(c, err)
+		return
+	}
+
+	if params.Hard {
+		if err := th.taskStore.Purge(ctx, id, opts...); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Purge failed")
+			mw.Error(c, err)
+			return
+		}
+	} else {
+		if err := th.taskStore.Delete(ctx, id, opts...); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Delete failed")
+			mw.Error(c, err)
+			return
+		}
+	}
+
+	mw.NoContent(c)
+}
+
+// @Summary Move task
+// @Description Places the task right after after_id, right before before_id or between both of them in the default order
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param id path string true "task's ID"
+// @Param If-Match header string false "ETag of the task, the move is rejected if it's stale"
+// @Param MoveTaskParams body models.MoveTaskParams true "neighbors of the task after the move"
+// @Success 200 {object} models.MoveTaskResp
+// @Header 200 {string} ETag "version of the task"
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 410 {object} models.BaseError
+// @Failure 412 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /task/{id}/move [post]
+func (th *taskHandler) moveTask(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	params := models.MoveTaskParams{}
+	if err := c.ShouldBindJSON(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindJSON failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	opts, err := ifMatch(c)
+	if err != nil {
+		mw.Error(c, err)
+		return
+	}
+
+	task, err := th.taskStore.Move(ctx, id, &params, opts...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Move failed")
 		mw.Error(c, err)
 		return
 	}
 
-	mw.JSON(c, http.StatusOK, gin.H{})
+	setETag(c, task)
+	mw.JSON(c, http.StatusOK, models.MoveTaskResp{
+		Result: task.Parse(),
+	})
+}
+
+// @Summary Restore task
+// @Description Undoes the soft deletion of the task
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param id path string true "task's ID"
+// @Param If-Match header string false "ETag of the task, the restoration is rejected if it's stale"
+// @Success 200 {object} models.RestoreTaskResp
+// @Header 200 {string} ETag "version of the task"
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 409 {object} models.BaseError
+// @Failure 412 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /task/{id}/restore [post]
+func (th *taskHandler) restoreTask(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	opts, err := ifMatch(c)
+	if err != nil {
+		mw.Error(c, err)
+		return
+	}
+
+	task, err := th.taskStore.Restore(ctx, id, opts...)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Restore failed")
+		mw.Error(c, err)
+		return
+	}
+
+	setETag(c, task)
+	mw.JSON(c, http.StatusOK, models.RestoreTaskResp{
+		Result: task.Parse(),
+	})
+}
+
+// @Summary Batch tasks
+// @Description Creates, updates and deletes tasks in a transaction. Update works like PATCH and version like If-Match.
+// @Description The response is 207 if any operation fails, check status of each result.
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param BatchTaskParams body models.BatchTaskParams true "operations to apply"
+// @Success 200 {object} models.BatchTaskResp
+// @Success 207 {object} models.BatchTaskResp
+// @Failure 400 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /tasks:batch [post]
+func (th *taskHandler) batchTask(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	params := models.BatchTaskParams{}
+	if err := c.ShouldBindJSON(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindJSON failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	results, err := th.taskStore.Batch(ctx, params.Operations, params.Atomic)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Batch failed")
+		mw.Error(c, err)
+		return
+	}
+
+	code := http.StatusOK
+	resp := models.BatchTaskResp{
+		Committed: true,
+		Result:    make([]*models.BatchTaskResult, len(results)),
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			status, e := mw.ErrorStatus(c, r.Err)
+			resp.Result[i] = &models.BatchTaskResult{Status: status, Error: e}
+			code = http.StatusMultiStatus
+			if params.Atomic {
+				resp.Committed = false
+			}
+			continue
+		}
+
+		switch params.Operations[i].Op {
+		case models.BatchOpCreate:
+			resp.Result[i] = &models.BatchTaskResult{Status: http.StatusCreated, Result: r.Task.Parse()}
+		case models.BatchOpDelete:
+			resp.Result[i] = &models.BatchTaskResult{Status: http.StatusNoContent}
+		default:
+			resp.Result[i] = &models.BatchTaskResult{Status: http.StatusOK, Result: r.Task.Parse()}
+		}
+	}
+
+	mw.JSON(c, code, resp)
 }
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	mw "github.com/chihkaiyu/task-todo-api/middlewares"
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/stores/webhooks"
+)
+
+type webhookHandler struct {
+	webhookStore webhooks.Webhook
+}
+
+func NewWebhookHandler(webhookRG *gin.RouterGroup, webhookStore webhooks.Webhook) {
+	wh := webhookHandler{
+		webhookStore: webhookStore,
+	}
+
+	webhookRG.GET("/webhooks", wh.listWebhook)
+	webhookRG.GET("/webhook/:id", wh.getWebhook)
+	webhookRG.GET("/webhook/:id/deliveries", wh.listWebhookDelivery)
+	webhookRG.POST("/webhook", wh.createWebhook)
+	webhookRG.PUT("/webhook/:id", wh.putWebhook)
+	webhookRG.DELETE("/webhook/:id", wh.deleteWebhook)
+}
+
+// @Summary List webhooks
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.ListWebhookResp
+// @Failure 500 {object} models.BaseError
+// @Router /webhooks [get]
+func (wh *webhookHandler) listWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	webhooks, err := wh.webhookStore.List(ctx)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("webhookStore.List failed")
+		mw.Error(c, err)
+		return
+	}
+
+	dw := make([]*models.DisplayWebhook, len(webhooks))
+	for i, w := range webhooks {
+		dw[i] = w.Parse()
+	}
+
+	mw.JSON(c, http.StatusOK, models.ListWebhookResp{
+		Result: dw,
+	})
+}
+
+// @Summary Get webhook
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "webhook's ID"
+// @Success 200 {object} models.GetWebhookResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /webhook/{id} [get]
+func (wh *webhookHandler) getWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	webhook, err := wh.webhookStore.Get(ctx, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("webhookStore.Get failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.JSON(c, http.StatusOK, models.GetWebhookResp{
+		Result: webhook.Parse(),
+	})
+}
+
+// @Summary List webhook deliveries
+// @Description Lists the latest deliveries of the webhook first. Dead deliveries are given up after too many failures.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "webhook's ID"
+// @Param status query string false "filter by status" Enums(pending, delivered, dead)
+// @Param limit query int false "page size, defaults to 20" minimum(0) maximum(100)
+// @Success 200 {object} models.ListWebhookDeliveryResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /webhook/{id}/deliveries [get]
+func (wh *webhookHandler) listWebhookDelivery(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	params := models.ListWebhookDeliveryParams{}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindQuery failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	deliveries, err := wh.webhookStore.ListDeliveries(ctx, id, &params)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("webhookStore.ListDeliveries failed")
+		mw.Error(c, err)
+		return
+	}
+
+	dd := make([]*models.DisplayWebhookDelivery, len(deliveries))
+	for i, d := range deliveries {
+		dd[i] = d.Parse()
+	}
+
+	mw.JSON(c, http.StatusOK, models.ListWebhookDeliveryResp{
+		Result: dd,
+	})
+}
+
+// @Summary Create webhook
+// @Description Subscribes the url to task.created, task.updated and task.deleted events, or to the ones in events if it isn't empty.
+// @Description Events are emitted by mutations of tasks only, blocker, label and list changes emit none.
+// @Description The secret signing the deliveries is generated for the webhook and returned only in this response.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param CreateWebhookParams body models.CreateWebhookParams true "parameters for creating webhook"
+// @Success 201 {object} models.CreateWebhookResp
+// @Failure 400 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /webhook [post]
+func (wh *webhookHandler) createWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	params := models.CreateWebhookParams{}
+	if err := c.ShouldBindJSON(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindJSON failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	webhook, err := wh.webhookStore.Create(ctx, &params)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("webhookStore.Create failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.JSON(c, http.StatusCreated, models.CreateWebhookResp{
+		Result: webhook.ParseCreated(),
+	})
+}
+
+// @Summary Put webhook
+// @Description Replaces the url and events of the webhook, events dispatched already are sent to the old url
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "webhook's ID"
+// @Param PutWebhookParams body models.PutWebhookParams true "parameters for updating webhook"
+// @Success 200 {object} models.PutWebhookResp
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /webhook/{id} [put]
+func (wh *webhookHandler) putWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	params := models.PutWebhookParams{}
+	if err := c.ShouldBindJSON(&params); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("c.ShouldBindJSON failed")
+		mw.Error(c, mw.BindingError(err))
+		return
+	}
+
+	webhook, err := wh.webhookStore.Put(ctx, id, &params)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("webhookStore.Put failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.JSON(c, http.StatusOK, models.PutWebhookResp{
+		Result: webhook.Parse(),
+	})
+}
+
+// @Summary Delete webhook
+// @Description Deletes the webhook along with its deliveries
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "webhook's ID"
+// @Success 204
+// @Failure 400 {object} models.BaseError
+// @Failure 404 {object} models.BaseError
+// @Failure 500 {object} models.BaseError
+// @Router /webhook/{id} [delete]
+func (wh *webhookHandler) deleteWebhook(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	if err := wh.webhookStore.Delete(ctx, id); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("webhookStore.Delete failed")
+		mw.Error(c, err)
+		return
+	}
+
+	mw.NoContent(c)
+}
@@ -1,10 +1,60 @@
 package config
 
+import (
+	"fmt"
+	"time"
+)
+
 type (
 	Config struct {
 		Env         string `env:"ENV" default:"local"`
 		Port        string `env:"PORT" default:"8080"`
 		Debug       bool   `env:"DEBUG" default:"false"`
 		PostgresURI string `env:"POSTGRES_URI" required:"true"`
+
+		// DBQueryTimeout cancels a query running longer than it, 0 means no timeout
+		DBQueryTimeout time.Duration `env:"DB_QUERY_TIMEOUT" default:"5s"`
+		// DBSlowQueryThreshold logs a query running longer than it, 0 disables the log
+		DBSlowQueryThreshold time.Duration `env:"DB_SLOW_QUERY_THRESHOLD" default:"200ms"`
+
+		// DeletedTaskRetention is how long soft-deleted tasks are kept before being purged, 0 keeps them forever
+		DeletedTaskRetention     time.Duration `env:"DELETED_TASK_RETENTION" default:"720h"`
+		DeletedTaskPurgeInterval time.Duration `env:"DELETED_TASK_PURGE_INTERVAL" default:"1h"`
+
+		// RecurrenceInterval is how often the next occurrences of recurring tasks are made, 0 disables it
+		RecurrenceInterval time.Duration `env:"RECURRENCE_INTERVAL" default:"1m"`
+
+		// ReminderWebhookURLs receive task.reminder events, reminders aren't sent without any of them
+		ReminderWebhookURLs []string      `env:"REMINDER_WEBHOOK_URLS"`
+		ReminderInterval    time.Duration `env:"REMINDER_INTERVAL" default:"30s"`
+		// WebhookSecret signs the payload of task.reminder events with HMAC-SHA256, reminders aren't sent without it.
+		// Webhooks subscribing task events sign theirs with their own secrets.
+		WebhookSecret     string        `env:"WEBHOOK_SECRET"`
+		WebhookTimeout    time.Duration `env:"WEBHOOK_TIMEOUT" default:"10s"`
+		WebhookMaxRetries int           `env:"WEBHOOK_MAX_RETRIES" default:"3"`
+		// WebhookAllowPrivate lets webhooks and reminders reach loopback, link-local and private addresses
+		WebhookAllowPrivate bool `env:"WEBHOOK_ALLOW_PRIVATE" default:"false"`
+		// WebhookInterval is how often task events are dispatched and sent to subscribed webhooks, 0 disables it
+		WebhookInterval time.Duration `env:"WEBHOOK_INTERVAL" default:"10s"`
+		// EventRetention is how long task events and their deliveries are kept, 0 keeps them forever.
+		// Event streams can't be resumed from the events pruned.
+		EventRetention     time.Duration `env:"EVENT_RETENTION" default:"168h"`
+		EventPurgeInterval time.Duration `env:"EVENT_PURGE_INTERVAL" default:"1h"`
+		// EventsHeartbeatInterval is how often a comment is sent on idle event streams, which must be positive
+		EventsHeartbeatInterval time.Duration `env:"EVENTS_HEARTBEAT_INTERVAL" default:"15s"`
+		// EventsMaxHold is how long events are held for the transactions before them at most, which must be positive.
+		// Events of transactions longer than it may be skipped by streams.
+		EventsMaxHold time.Duration `env:"EVENTS_MAX_HOLD" default:"1m"`
 	}
 )
+
+// Validate checks the values which can't be fixed up when they're used
+func (c *Config) Validate() error {
+	if c.EventsHeartbeatInterval <= 0 {
+		return fmt.Errorf("EVENTS_HEARTBEAT_INTERVAL must be positive, got %s", c.EventsHeartbeatInterval)
+	}
+	if c.EventsMaxHold <= 0 {
+		return fmt.Errorf("EVENTS_MAX_HOLD must be positive, got %s", c.EventsMaxHold)
+	}
+	return nil
+}
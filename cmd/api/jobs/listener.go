@@ -0,0 +1,19 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/base/goroutine"
+	"github.com/chihkaiyu/task-todo-api/services/postgres"
+)
+
+// StartListener broadcasts the notifications of the listener to its subscribers until ctx is done
+func StartListener(ctx context.Context, listener *postgres.Listener) chan *goroutine.PanicEvent {
+	return goroutine.Go(func() {
+		if err := listener.Listen(ctx); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("listener.Listen failed")
+		}
+	})
+}
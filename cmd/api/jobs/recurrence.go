@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/base/goroutine"
+	"github.com/chihkaiyu/task-todo-api/stores/tasks"
+)
+
+// recurrenceBatchSize is how many recurring tasks are handled in a transaction
+const recurrenceBatchSize = 100
+
+// StartRecurrence makes the next occurrences of recurring tasks which are done or due every interval,
+// until ctx is done. Replicas running it at the same time handle different tasks.
+func StartRecurrence(ctx context.Context, taskStore tasks.Task, interval time.Duration) chan *goroutine.PanicEvent {
+	return goroutine.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			// NOTE: keep going while batches are full, so that a backlog doesn't wait for the next tick
+			for ctx.Err() == nil {
+				n, err := taskStore.Recur(ctx, timeNow().UTC(), recurrenceBatchSize)
+				if err != nil {
+					zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.Recur failed")
+					break
+				}
+				if n > 0 {
+					zerolog.Ctx(ctx).Info().Int("recurred", n).Msg("recurring tasks handled")
+				}
+				if n < recurrenceBatchSize {
+					break
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// Wait waits for the jobs to return until ctx is done
+func Wait(ctx context.Context, jobs ...chan *goroutine.PanicEvent) {
+	for _, job := range jobs {
+		select {
+		case <-job:
+		case <-ctx.Done():
+			zerolog.Ctx(ctx).Warn().Err(ctx.Err()).Msg("jobs didn't stop in time")
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/base/goroutine"
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/services/webhook"
+	"github.com/chihkaiyu/task-todo-api/stores/reminders"
+)
+
+const (
+	reminderEvent = "task.reminder"
+
+	// reminderLeaseMargin is added to the longest time sending a reminder takes to lease it
+	reminderLeaseMargin = time.Minute
+	// reminderMaxAttempts is how many times a reminder is claimed before it's given up, the wait before
+	// claiming it again starts from reminderBackoff and doubles every attempt
+	reminderMaxAttempts = 5
+	reminderBackoff     = time.Minute
+)
+
+// StartReminders sends the reminders which fire to every url signed by secret every interval, until ctx is done.
+// Replicas running it at the same time send different reminders.
+func StartReminders(ctx context.Context, reminderStore reminders.Reminder, sender webhook.Sender, urls []string, secret string, interval time.Duration) chan *goroutine.PanicEvent {
+	// NOTE: reminders are claimed one at a time, so the lease only has to cover sending one to every url
+	lease := time.Duration(len(urls))*sender.MaxDuration() + reminderLeaseMargin
+	return goroutine.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			for ctx.Err() == nil {
+				due, err := reminderStore.Claim(ctx, timeNow().UTC(), lease, 1)
+				if err != nil {
+					zerolog.Ctx(ctx).Error().Err(err).Msg("reminderStore.Claim failed")
+					break
+				}
+				if len(due) == 0 {
+					break
+				}
+				sendReminder(ctx, reminderStore, sender, urls, secret, due[0])
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// sendReminder sends the claimed reminder and records the result. Receivers which got it already get it
+// again when another one fails, they can drop it by the event ID, which is the reminder's ID.
+func sendReminder(ctx context.Context, reminderStore reminders.Reminder, sender webhook.Sender, urls []string, secret string, r *models.DueReminder) {
+	event := &models.WebhookEvent{
+		ID:        r.ID,
+		Event:     reminderEvent,
+		CreatedAt: timeNow().UTC(),
+		Data:      r.EventData(),
+	}
+
+	var sendErr error
+	for _, url := range urls {
+		if err := sender.Send(ctx, url, secret, event); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("reminder_id", r.ID.String()).Str("url", url).Msg("sender.Send failed")
+			sendErr = err
+		}
+	}
+
+	now := timeNow().UTC()
+	var err error
+	switch {
+	case sendErr == nil:
+		err = reminderStore.MarkSent(ctx, r.ID, r.Attempts, now)
+	case r.Attempts >= reminderMaxAttempts:
+		err = reminderStore.MarkFailed(ctx, r.ID, r.Attempts, sendErr.Error(), now, time.Time{})
+	default:
+		retryAt := now.Add(reminderBackoff << (r.Attempts - 1))
+		err = reminderStore.MarkFailed(ctx, r.ID, r.Attempts, sendErr.Error(), now, retryAt)
+	}
+	if err == reminders.ErrClaimLost {
+		// NOTE: the lease passed and the reminder belongs to whoever claimed it again
+		zerolog.Ctx(ctx).Warn().Err(err).Str("reminder_id", r.ID.String()).Msg("reminder claim lost")
+	} else if err != nil {
+		// NOTE: the reminder is claimed again once the lease passes
+		zerolog.Ctx(ctx).Error().Err(err).Str("reminder_id", r.ID.String()).Msg("reminderStore.Mark failed")
+	}
+}
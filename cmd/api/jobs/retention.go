@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/base/goroutine"
+	"github.com/chihkaiyu/task-todo-api/stores/tasks"
+	"github.com/chihkaiyu/task-todo-api/stores/webhooks"
+)
+
+var timeNow = time.Now
+
+// StartRetention purges the tasks which have been soft-deleted longer than retention
+// every interval, until ctx is done.
+func StartRetention(ctx context.Context, taskStore tasks.Task, retention, interval time.Duration) chan *goroutine.PanicEvent {
+	return goroutine.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			before := timeNow().UTC().Add(-retention)
+			n, err := taskStore.PurgeDeleted(ctx, before)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("taskStore.PurgeDeleted failed")
+			} else if n > 0 {
+				zerolog.Ctx(ctx).Info().Int("purged", n).Time("before", before).Msg("deleted tasks purged")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// StartEventRetention prunes the task events which are older than retention, along with their deliveries
+// to webhooks, every interval until ctx is done.
+func StartEventRetention(ctx context.Context, webhookStore webhooks.Webhook, retention, interval time.Duration) chan *goroutine.PanicEvent {
+	return goroutine.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			before := timeNow().UTC().Add(-retention)
+			n, err := webhookStore.Prune(ctx, before)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("webhookStore.Prune failed")
+			} else if n > 0 {
+				zerolog.Ctx(ctx).Info().Int("pruned", n).Time("before", before).Msg("task events pruned")
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
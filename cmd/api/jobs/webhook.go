@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/base/goroutine"
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/services/webhook"
+	"github.com/chihkaiyu/task-todo-api/stores/webhooks"
+)
+
+const (
+	// dispatchBatchSize is how many events in the outbox are dispatched to deliveries at once
+	dispatchBatchSize = 100
+
+	// deliveryLeaseMargin is added to the longest time sending a delivery takes to lease it
+	deliveryLeaseMargin = time.Minute
+	// deliveryMaxAttempts is how many times a delivery is claimed before it's dead, the wait before
+	// claiming it again starts from deliveryBackoff and doubles every attempt
+	deliveryMaxAttempts = 8
+	deliveryBackoff     = time.Minute
+)
+
+// StartWebhooks dispatches the task events in the outbox to the webhooks subscribing them and sends the
+// deliveries every interval, until ctx is done. Replicas running it at the same time send different deliveries.
+func StartWebhooks(ctx context.Context, webhookStore webhooks.Webhook, sender webhook.Sender, interval time.Duration) chan *goroutine.PanicEvent {
+	// NOTE: deliveries are claimed one at a time, so the lease only has to cover sending one
+	lease := sender.MaxDuration() + deliveryLeaseMargin
+	return goroutine.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			for ctx.Err() == nil {
+				n, err := webhookStore.Dispatch(ctx, timeNow().UTC(), dispatchBatchSize)
+				if err != nil {
+					zerolog.Ctx(ctx).Error().Err(err).Msg("webhookStore.Dispatch failed")
+					break
+				}
+				if n < dispatchBatchSize {
+					break
+				}
+			}
+
+			for ctx.Err() == nil {
+				due, err := webhookStore.Claim(ctx, timeNow().UTC(), lease, 1)
+				if err != nil {
+					zerolog.Ctx(ctx).Error().Err(err).Msg("webhookStore.Claim failed")
+					break
+				}
+				if len(due) == 0 {
+					break
+				}
+				sendDelivery(ctx, webhookStore, sender, due[0])
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// sendDelivery sends the claimed delivery and records the result, the delivery is dead once it fails
+// deliveryMaxAttempts times. Receivers can drop duplicates by the event ID.
+func sendDelivery(ctx context.Context, webhookStore webhooks.Webhook, sender webhook.Sender, d *models.DueWebhookDelivery) {
+	sendErr := sender.Send(ctx, d.URL, d.Secret, d.WebhookEvent())
+	if sendErr != nil {
+		zerolog.Ctx(ctx).Error().Err(sendErr).Str("delivery_id", d.ID.String()).Str("url", d.URL).Msg("sender.Send failed")
+	}
+
+	now := timeNow().UTC()
+	var err error
+	switch {
+	case sendErr == nil:
+		err = webhookStore.MarkDelivered(ctx, d.ID, d.Attempts, now)
+	case d.Attempts >= deliveryMaxAttempts:
+		err = webhookStore.MarkFailed(ctx, d.ID, d.Attempts, sendErr.Error(), now, time.Time{})
+	default:
+		retryAt := now.Add(deliveryBackoff << (d.Attempts - 1))
+		err = webhookStore.MarkFailed(ctx, d.ID, d.Attempts, sendErr.Error(), now, retryAt)
+	}
+	if err == webhooks.ErrClaimLost {
+		// NOTE: the lease passed and the delivery belongs to whoever claimed it again
+		zerolog.Ctx(ctx).Warn().Err(err).Str("delivery_id", d.ID.String()).Msg("delivery claim lost")
+	} else if err != nil {
+		// NOTE: the delivery is claimed again once the lease passes
+		zerolog.Ctx(ctx).Error().Err(err).Str("delivery_id", d.ID.String()).Msg("webhookStore.Mark failed")
+	}
+}
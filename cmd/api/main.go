@@ -24,12 +24,20 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	bconfig "github.com/chihkaiyu/task-todo-api/base/config"
+	"github.com/chihkaiyu/task-todo-api/base/goroutine"
 	"github.com/chihkaiyu/task-todo-api/base/server"
 	"github.com/chihkaiyu/task-todo-api/cmd/api/api"
 	"github.com/chihkaiyu/task-todo-api/cmd/api/config"
+	"github.com/chihkaiyu/task-todo-api/cmd/api/jobs"
 	"github.com/chihkaiyu/task-todo-api/middlewares"
 	"github.com/chihkaiyu/task-todo-api/services/postgres"
+	"github.com/chihkaiyu/task-todo-api/services/webhook"
+	"github.com/chihkaiyu/task-todo-api/stores/events"
+	"github.com/chihkaiyu/task-todo-api/stores/labels"
+	"github.com/chihkaiyu/task-todo-api/stores/lists"
+	"github.com/chihkaiyu/task-todo-api/stores/reminders"
 	"github.com/chihkaiyu/task-todo-api/stores/tasks"
+	"github.com/chihkaiyu/task-todo-api/stores/webhooks"
 
 	_ "github.com/chihkaiyu/task-todo-api/cmd/api/docs"
 )
@@ -50,6 +58,9 @@ func main() {
 	if err := bconfig.Parse(&cfg); err != nil {
 		rootLogger.Fatal().Err(err).Msg("bconfig.Parse failed")
 	}
+	if err := cfg.Validate(); err != nil {
+		rootLogger.Fatal().Err(err).Msg("cfg.Validate failed")
+	}
 
 	dbPG, err := postgres.New(cfg.PostgresURI)
 	if err != nil {
@@ -57,7 +68,24 @@ func main() {
 	}
 
 	// stores
-	taskStore := tasks.New(dbPG)
+	queryOpts := []postgres.QueryOptionFunc{
+		postgres.WithQueryTimeout(cfg.DBQueryTimeout),
+		postgres.WithSlowQueryThreshold(cfg.DBSlowQueryThreshold),
+	}
+	taskStore := tasks.New(dbPG, queryOpts...)
+	labelStore := labels.New(dbPG, queryOpts...)
+	listStore := lists.New(dbPG, queryOpts...)
+	reminderStore := reminders.New(dbPG, queryOpts...)
+	webhookStore := webhooks.New(dbPG, queryOpts...)
+	eventStore := events.New(dbPG, queryOpts...)
+
+	// services
+	webhookSender := webhook.New(
+		webhook.WithTimeout(cfg.WebhookTimeout),
+		webhook.WithMaxRetries(cfg.WebhookMaxRetries),
+		webhook.WithAllowPrivate(cfg.WebhookAllowPrivate),
+	)
+	taskEventListener := postgres.NewListener(cfg.PostgresURI, events.Channel)
 
 	router := gin.New()
 	router.Use(
@@ -75,12 +103,51 @@ func main() {
 	if cfg.Debug {
 		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
+	router.NoRoute(api.CustomMethod)
 	rg := router.Group("/")
 
 	// routers
 	api.NewTaskHandler(rg, taskStore)
+	api.NewLabelHandler(rg, labelStore)
+	api.NewListHandler(rg, listStore, taskStore)
+	api.NewReminderHandler(rg, reminderStore)
+	api.NewWebhookHandler(rg, webhookStore)
+	api.NewEventHandler(rg, eventStore, taskEventListener, cfg.EventsHeartbeatInterval, cfg.EventsMaxHold)
+
+	// jobs
+	jobCtx, stopJobs := context.WithCancel(rootCtx)
+	defer stopJobs()
+	runningJobs := []chan *goroutine.PanicEvent{
+		jobs.StartListener(jobCtx, taskEventListener),
+	}
+	if cfg.DeletedTaskRetention > 0 && cfg.DeletedTaskPurgeInterval > 0 {
+		runningJobs = append(runningJobs, jobs.StartRetention(jobCtx, taskStore, cfg.DeletedTaskRetention, cfg.DeletedTaskPurgeInterval))
+	}
+	if cfg.EventRetention > 0 && cfg.EventPurgeInterval > 0 {
+		runningJobs = append(runningJobs, jobs.StartEventRetention(jobCtx, webhookStore, cfg.EventRetention, cfg.EventPurgeInterval))
+	}
+	if cfg.RecurrenceInterval > 0 {
+		runningJobs = append(runningJobs, jobs.StartRecurrence(jobCtx, taskStore, cfg.RecurrenceInterval))
+	}
+	if len(cfg.ReminderWebhookURLs) > 0 && cfg.ReminderInterval > 0 {
+		// NOTE: anyone can forge the signature made with an empty secret, so reminders aren't sent without it
+		if cfg.WebhookSecret == "" {
+			rootLogger.Error().Msg("WEBHOOK_SECRET is empty, reminders aren't sent")
+		} else {
+			runningJobs = append(runningJobs, jobs.StartReminders(jobCtx, reminderStore, webhookSender, cfg.ReminderWebhookURLs, cfg.WebhookSecret, cfg.ReminderInterval))
+		}
+	}
+	// NOTE: deliveries are signed by the secret of each webhook
+	if cfg.WebhookInterval > 0 {
+		runningJobs = append(runningJobs, jobs.StartWebhooks(jobCtx, webhookStore, webhookSender, cfg.WebhookInterval))
+	}
 
-	if err := server.Serve(fmt.Sprintf(":%s", cfg.Port), router); err != nil {
+	stopRunningJobs := func(ctx context.Context) {
+		stopJobs()
+		// NOTE: the context of hooks carries no logger
+		jobs.Wait(rootLogger.WithContext(ctx), runningJobs...)
+	}
+	if err := server.Serve(fmt.Sprintf(":%s", cfg.Port), router, stopRunningJobs); err != nil {
 		rootLogger.Fatal().Err(err).Msg("server.Serve failed:")
 	}
 }
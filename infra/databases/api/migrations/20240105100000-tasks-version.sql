@@ -0,0 +1,6 @@
+
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- +migrate Down
+ALTER TABLE tasks DROP COLUMN IF EXISTS version;
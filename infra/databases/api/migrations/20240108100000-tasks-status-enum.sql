@@ -0,0 +1,16 @@
+
+-- +migrate Up
+ALTER TABLE tasks ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE tasks ALTER COLUMN status TYPE VARCHAR(20) USING (
+    CASE status WHEN 1 THEN 'done' ELSE 'todo' END
+);
+ALTER TABLE tasks ALTER COLUMN status SET DEFAULT 'todo';
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check CHECK (status IN ('todo', 'in_progress', 'done', 'archived'));
+
+-- +migrate Down
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_status_check;
+ALTER TABLE tasks ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE tasks ALTER COLUMN status TYPE SMALLINT USING (
+    CASE status WHEN 'done' THEN 1 ELSE 0 END
+);
+ALTER TABLE tasks ALTER COLUMN status SET DEFAULT 0;
@@ -0,0 +1,7 @@
+
+-- +migrate Up
+-- NOTE: deleted_at was always written in UTC
+ALTER TABLE tasks ALTER COLUMN deleted_at TYPE TIMESTAMP WITH TIME ZONE USING deleted_at AT TIME ZONE 'UTC';
+
+-- +migrate Down
+ALTER TABLE tasks ALTER COLUMN deleted_at TYPE TIMESTAMP USING deleted_at AT TIME ZONE 'UTC';
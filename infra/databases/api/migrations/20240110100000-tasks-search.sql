@@ -0,0 +1,9 @@
+
+-- +migrate Up
+-- NOTE: the simple configuration doesn't stem words, names are often not in English
+ALTER TABLE tasks ADD COLUMN search TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', name)) STORED;
+CREATE INDEX tasks_search_idx ON tasks USING GIN (search);
+
+-- +migrate Down
+DROP INDEX tasks_search_idx;
+ALTER TABLE tasks DROP COLUMN search;
@@ -0,0 +1,34 @@
+
+-- +migrate Up
+ALTER TABLE tasks ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD CONSTRAINT tasks_priority_check CHECK (priority BETWEEN 0 AND 3);
+ALTER TABLE tasks ADD COLUMN due_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+ALTER TABLE tasks ADD COLUMN completed_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+-- NOTE: when the tasks were completed is unknown, the last update is the closest guess
+UPDATE tasks SET completed_at=updated_at WHERE status='done';
+
+CREATE INDEX tasks_due_at_idx ON tasks ((COALESCE(due_at, 'infinity')), pk);
+CREATE INDEX tasks_priority_idx ON tasks (priority, pk);
+
+-- NOTE: description is searched as well, with lower weight than name
+DROP INDEX tasks_search_idx;
+ALTER TABLE tasks DROP COLUMN search;
+ALTER TABLE tasks ADD COLUMN search TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', name), 'A') || setweight(to_tsvector('simple', description), 'B')
+) STORED;
+CREATE INDEX tasks_search_idx ON tasks USING GIN (search);
+
+-- +migrate Down
+DROP INDEX tasks_search_idx;
+ALTER TABLE tasks DROP COLUMN search;
+ALTER TABLE tasks ADD COLUMN search TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', name)) STORED;
+CREATE INDEX tasks_search_idx ON tasks USING GIN (search);
+
+DROP INDEX tasks_priority_idx;
+DROP INDEX tasks_due_at_idx;
+ALTER TABLE tasks DROP COLUMN completed_at;
+ALTER TABLE tasks DROP COLUMN due_at;
+ALTER TABLE tasks DROP CONSTRAINT tasks_priority_check;
+ALTER TABLE tasks DROP COLUMN priority;
+ALTER TABLE tasks DROP COLUMN description;
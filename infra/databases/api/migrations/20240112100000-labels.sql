@@ -0,0 +1,24 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS labels (
+    pk SERIAL PRIMARY KEY NOT NULL,
+    id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX labels_id_idx ON labels (id);
+CREATE UNIQUE INDEX labels_name_idx ON labels (name);
+
+CREATE TABLE IF NOT EXISTS task_labels (
+    task_pk INTEGER NOT NULL REFERENCES tasks (pk) ON DELETE CASCADE,
+    label_pk INTEGER NOT NULL REFERENCES labels (pk) ON DELETE CASCADE,
+    PRIMARY KEY (task_pk, label_pk)
+);
+
+CREATE INDEX task_labels_label_pk_idx ON task_labels (label_pk);
+
+-- +migrate Down
+DROP TABLE IF EXISTS task_labels;
+DROP TABLE IF EXISTS labels;
@@ -0,0 +1,9 @@
+
+-- +migrate Up
+-- NOTE: subtasks are soft-deleted along with their parent by the application, and purged along by the database
+ALTER TABLE tasks ADD COLUMN parent_id UUID DEFAULT NULL REFERENCES tasks (id) ON DELETE CASCADE;
+CREATE INDEX tasks_parent_id_idx ON tasks (parent_id);
+
+-- +migrate Down
+DROP INDEX tasks_parent_id_idx;
+ALTER TABLE tasks DROP COLUMN parent_id;
@@ -0,0 +1,14 @@
+
+-- +migrate Up
+-- NOTE: task_pk is blocked by blocker_pk
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_pk INTEGER NOT NULL REFERENCES tasks (pk) ON DELETE CASCADE,
+    blocker_pk INTEGER NOT NULL REFERENCES tasks (pk) ON DELETE CASCADE,
+    PRIMARY KEY (task_pk, blocker_pk),
+    CONSTRAINT task_dependencies_self_check CHECK (task_pk <> blocker_pk)
+);
+
+CREATE INDEX task_dependencies_blocker_pk_idx ON task_dependencies (blocker_pk);
+
+-- +migrate Down
+DROP TABLE IF EXISTS task_dependencies;
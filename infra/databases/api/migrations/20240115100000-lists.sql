@@ -0,0 +1,21 @@
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS lists (
+    pk SERIAL PRIMARY KEY NOT NULL,
+    id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    archived_at TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX lists_id_idx ON lists (id);
+
+-- NOTE: lists having tasks can't be deleted by the application, only deleted tasks are moved out of them here
+ALTER TABLE tasks ADD COLUMN list_id UUID DEFAULT NULL REFERENCES lists (id) ON DELETE SET NULL;
+CREATE INDEX tasks_list_id_idx ON tasks (list_id);
+
+-- +migrate Down
+DROP INDEX tasks_list_id_idx;
+ALTER TABLE tasks DROP COLUMN list_id;
+DROP TABLE IF EXISTS lists;
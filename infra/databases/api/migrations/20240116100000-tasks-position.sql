@@ -0,0 +1,13 @@
+
+-- +migrate Up
+-- NOTE: positions are lexorank keys compared in byte order, existing tasks are placed in the order they were created
+ALTER TABLE tasks ADD COLUMN position TEXT COLLATE "C";
+UPDATE tasks SET position='i' || lpad(r.n::TEXT, 10, '0') || 'i'
+FROM (SELECT pk, row_number() OVER (ORDER BY created_at, pk) AS n FROM tasks) r WHERE tasks.pk=r.pk;
+ALTER TABLE tasks ALTER COLUMN position SET NOT NULL;
+-- NOTE: positions are unique so that there is always room to move a task between two others
+CREATE UNIQUE INDEX tasks_position_idx ON tasks (position);
+
+-- +migrate Down
+DROP INDEX tasks_position_idx;
+ALTER TABLE tasks DROP COLUMN position;
@@ -0,0 +1,20 @@
+
+-- +migrate Up
+-- NOTE: occurrences of a recurring task share series_id, which is the ID of the first occurrence and is left NULL
+-- until the task recurs. recurred_at is when the next occurrence was made, at most one is made per occurrence.
+ALTER TABLE tasks ADD COLUMN rrule TEXT DEFAULT NULL;
+ALTER TABLE tasks ADD COLUMN series_id UUID DEFAULT NULL;
+ALTER TABLE tasks ADD COLUMN occurrence INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE tasks ADD COLUMN recurred_at TIMESTAMP WITH TIME ZONE DEFAULT NULL;
+ALTER TABLE tasks ADD CONSTRAINT tasks_rrule_due_at_check CHECK (rrule IS NULL OR due_at IS NOT NULL);
+CREATE UNIQUE INDEX tasks_series_id_occurrence_idx ON tasks (series_id, occurrence);
+CREATE INDEX tasks_recurrence_idx ON tasks (due_at) WHERE rrule IS NOT NULL AND recurred_at IS NULL AND deleted_at IS NULL;
+
+-- +migrate Down
+DROP INDEX tasks_recurrence_idx;
+DROP INDEX tasks_series_id_occurrence_idx;
+ALTER TABLE tasks DROP CONSTRAINT tasks_rrule_due_at_check;
+ALTER TABLE tasks DROP COLUMN recurred_at;
+ALTER TABLE tasks DROP COLUMN occurrence;
+ALTER TABLE tasks DROP COLUMN series_id;
+ALTER TABLE tasks DROP COLUMN rrule;
@@ -0,0 +1,25 @@
+
+-- +migrate Up
+-- NOTE: a reminder fires at remind_at, or offset_minutes after the due date of the task if it's relative.
+-- next_attempt_at is when it can be claimed again after a failure or while a dispatcher holds it.
+CREATE TABLE IF NOT EXISTS reminders (
+    pk SERIAL PRIMARY KEY NOT NULL,
+    id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    task_id UUID NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    remind_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    offset_minutes INTEGER DEFAULT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    failed_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    last_error TEXT DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((remind_at IS NULL) <> (offset_minutes IS NULL))
+);
+
+CREATE UNIQUE INDEX reminders_id_idx ON reminders (id);
+CREATE INDEX reminders_task_id_idx ON reminders (task_id);
+CREATE INDEX reminders_pending_idx ON reminders (pk) WHERE sent_at IS NULL AND failed_at IS NULL;
+
+-- +migrate Down
+DROP TABLE IF EXISTS reminders;
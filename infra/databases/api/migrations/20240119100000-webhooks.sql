@@ -0,0 +1,56 @@
+
+-- +migrate Up
+-- NOTE: a webhook receives the task events named in events, or all of them if it's empty.
+-- The deliveries are signed by secret, which is the webhook's own so that subscribers can't forge events to each other.
+CREATE TABLE IF NOT EXISTS webhooks (
+    pk SERIAL PRIMARY KEY NOT NULL,
+    id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    url TEXT NOT NULL,
+    events TEXT[] NOT NULL DEFAULT '{}',
+    secret TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX webhooks_id_idx ON webhooks (id);
+
+-- NOTE: task events are written in the transaction of the mutation, and dispatched to the deliveries
+-- of the webhooks subscribing them afterwards. task_id isn't a foreign key so that purged tasks keep their events.
+CREATE TABLE IF NOT EXISTS outbox (
+    pk BIGSERIAL PRIMARY KEY NOT NULL,
+    id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    event VARCHAR(50) NOT NULL,
+    task_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    dispatched_at TIMESTAMP WITH TIME ZONE DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX outbox_id_idx ON outbox (id);
+CREATE INDEX outbox_pending_idx ON outbox (pk) WHERE dispatched_at IS NULL;
+CREATE INDEX outbox_created_at_idx ON outbox (created_at);
+
+-- NOTE: next_attempt_at is when the delivery can be claimed again after a failure or while a worker holds it,
+-- dead_at is when it's given up
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    pk BIGSERIAL PRIMARY KEY NOT NULL,
+    id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    webhook_id UUID NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES outbox (id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    delivered_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    dead_at TIMESTAMP WITH TIME ZONE DEFAULT NULL,
+    last_error TEXT DEFAULT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX webhook_deliveries_id_idx ON webhook_deliveries (id);
+CREATE UNIQUE INDEX webhook_deliveries_webhook_id_event_id_idx ON webhook_deliveries (webhook_id, event_id);
+CREATE INDEX webhook_deliveries_event_id_idx ON webhook_deliveries (event_id);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (pk) WHERE delivered_at IS NULL AND dead_at IS NULL;
+
+-- +migrate Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS webhooks;
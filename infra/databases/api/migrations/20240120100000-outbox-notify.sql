@@ -0,0 +1,25 @@
+
+-- +migrate Up
+-- NOTE: xid is the transaction writing the event. Events are streamed in the order of xid, and held until every
+-- transaction with a lower xid ends, so that an event committed later never comes before the ones streamed already.
+ALTER TABLE outbox ADD COLUMN xid xid8 NOT NULL DEFAULT pg_current_xact_id();
+CREATE INDEX outbox_xid_pk_idx ON outbox (xid, pk);
+
+-- NOTE: an event written to the outbox is notified on task_events when the transaction commits, so that every
+-- API replica wakes its streams up to read the outbox. The payload is the pk of the event.
+-- +migrate StatementBegin
+CREATE OR REPLACE FUNCTION notify_task_event() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('task_events', NEW.pk::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +migrate StatementEnd
+
+CREATE TRIGGER outbox_notify AFTER INSERT ON outbox FOR EACH ROW EXECUTE FUNCTION notify_task_event();
+
+-- +migrate Down
+DROP TRIGGER IF EXISTS outbox_notify ON outbox;
+DROP FUNCTION IF EXISTS notify_task_event();
+DROP INDEX IF EXISTS outbox_xid_pk_idx;
+ALTER TABLE outbox DROP COLUMN IF EXISTS xid;
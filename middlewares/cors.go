@@ -8,7 +8,8 @@ import (
 func Cors(envString string) gin.HandlerFunc {
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Content-Length", "Authorization", "X-API-Key"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Content-Length", "Authorization", "X-API-Key", "If-Match"}
+	config.ExposeHeaders = []string{"ETag"}
 
 	return cors.New(config)
 }
@@ -24,7 +24,8 @@ func Logger(rootCtx context.Context) gin.HandlerFunc {
 			Str("path", c.Request.URL.Path). // NOTE: don't use c.FullPath(), we need parameter in path
 			Str("method", c.Request.Method).
 			Logger()
-		c.Request = c.Request.WithContext(logger.WithContext(rootCtx))
+		// NOTE: derive from the request context, so that queries are canceled once the client goes away
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context()))
 
 		c.Next()
 
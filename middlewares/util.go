@@ -2,11 +2,15 @@ package middlewares
 
 import (
 	"net/http"
+	"strings"
 
+	"github.com/gin-contrib/requestid"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
 
 	"github.com/chihkaiyu/task-todo-api/models"
 	"github.com/chihkaiyu/task-todo-api/services/metrics"
+	"github.com/chihkaiyu/task-todo-api/services/postgres"
 )
 
 var met = metrics.New("api")
@@ -15,36 +19,78 @@ func JSON(c *gin.Context, code int, obj interface{}) {
 	c.JSON(code, obj)
 }
 
+func NoContent(c *gin.Context) {
+	c.Status(http.StatusNoContent)
+}
+
 func Data(c *gin.Context, code int, contentType string, data []byte) {
 	c.Data(code, contentType, data)
 }
 
+// Error responds err with its status code, errors not defined in models are translated
+// to INTERNAL_ERROR so that details like SQL never leak to clients.
+// Clients accepting application/problem+json get an RFC 7807 problem instead of BaseError.
 func Error(c *gin.Context, err interface{}) {
-	var code int
+	code, e := ErrorStatus(c, err)
+	if acceptProblem(c) {
+		c.Header("Content-Type", models.MIMEProblemJSON)
+		c.JSON(code, models.NewProblem(code, e, c.Request.URL.Path, requestid.Get(c)))
+		return
+	}
+	c.JSON(code, e)
+}
+
+// ErrorStatus returns the status code of err and the error which is safe to show to clients
+func ErrorStatus(c *gin.Context, err interface{}) (int, error) {
+	if e, ok := err.(error); ok {
+		err = postgres.TranslateError(e)
+	}
+
 	switch e := err.(type) {
-	case models.BadRequestErr:
-		code = http.StatusBadRequest
+	case models.BadRequestErr, models.ValidationErr:
+		return http.StatusBadRequest, e.(error)
 	case models.AuthorizationErr:
-		code = http.StatusUnauthorized
+		return http.StatusUnauthorized, e
 	case models.ForbiddenErr:
-		code = http.StatusForbidden
+		return http.StatusForbidden, e
 	case models.NotFoundErr:
-		code = http.StatusNotFound
+		return http.StatusNotFound, e
+	case models.GoneErr:
+		return http.StatusGone, e
 	case models.NotAllowedErr:
-		code = http.StatusMethodNotAllowed
+		return http.StatusMethodNotAllowed, e
 	case models.TooManyRequestErr:
-		code = http.StatusTooManyRequests
+		return http.StatusTooManyRequests, e
 	case models.ConflictErr:
-		code = http.StatusConflict
+		return http.StatusConflict, e
+	case models.UnsupportedMediaTypeErr:
+		return http.StatusUnsupportedMediaType, e
+	case models.PreconditionFailedErr:
+		return http.StatusPreconditionFailed, e
+	case models.ServiceUnavailableErr:
+		return http.StatusServiceUnavailable, e
+	case models.BaseError:
+		return http.StatusInternalServerError, e
 	case error:
-		code = http.StatusInternalServerError
-		err = models.BaseError{Code: e.Error()}
+		zerolog.Ctx(c.Request.Context()).Error().Err(e).Msg("unexpected error")
+		return http.StatusInternalServerError, models.InternalError
 	default:
-		code = http.StatusInternalServerError
-		err = models.InternalError
+		return http.StatusInternalServerError, models.InternalError
 	}
+}
 
-	c.JSON(code, err)
+// acceptProblem tells if the client asks for problem details explicitly,
+// wildcards don't count so that existing clients keep getting BaseError
+func acceptProblem(c *gin.Context) bool {
+	for _, accept := range c.Request.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType := strings.TrimSpace(strings.SplitN(mediaRange, ";", 2)[0])
+			if strings.EqualFold(mediaType, models.MIMEProblemJSON) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func RecoveryHandle(c *gin.Context, err interface{}) {
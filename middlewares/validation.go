@@ -0,0 +1,91 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+var ErrInvalidParams = models.BadRequestErr{Code: "INVALID_PARAMS"}
+
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
+	}
+}
+
+// fieldName names a field after its json or form tag so that errors refer to what clients sent
+func fieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name := strings.SplitN(f.Tag.Get(tag), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+// BindingError converts the error of binding or validating request parameters
+// to a bad request error, listing the invalid fields when they are known.
+func BindingError(err error) error {
+	var ves validator.ValidationErrors
+	if errors.As(err, &ves) {
+		fields := make([]models.FieldError, len(ves))
+		for i, fe := range ves {
+			// NOTE: namespace locates nested fields, e.g. operations[0].name, after the struct's name
+			field := fe.Namespace()
+			if i := strings.Index(field, "."); i >= 0 {
+				field = field[i+1:]
+			}
+			fields[i] = models.FieldError{
+				Field:  field,
+				Reason: reason(fe),
+			}
+		}
+		return models.ValidationErr{Code: ErrInvalidParams.Code, Fields: fields}
+	}
+
+	var ute *json.UnmarshalTypeError
+	if errors.As(err, &ute) && ute.Field != "" {
+		return models.ValidationErr{
+			Code: ErrInvalidParams.Code,
+			Fields: []models.FieldError{
+				{Field: ute.Field, Reason: fmt.Sprintf("must be %s", ute.Type)},
+			},
+		}
+	}
+
+	return ErrInvalidParams
+}
+
+func reason(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required", "required_if", "required_unless", "required_without":
+		return "is required"
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "http_url":
+		return "must be an http or https URL"
+	case "oneof":
+		return fmt.Sprintf("must be one of [%s]", fe.Param())
+	case "excludesall":
+		return fmt.Sprintf("must not contain any of [%s]", strings.ReplaceAll(fe.Param(), "0x2C", ","))
+	default:
+		return fmt.Sprintf("failed on %s", fe.Tag())
+	}
+}
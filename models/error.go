@@ -7,21 +7,41 @@ type BaseError struct {
 
 var InternalError = BaseError{Code: "INTERNAL_ERROR"}
 
+// FieldError describes why a field of request is invalid
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ValidationErr is a bad request error listing every invalid field
+type ValidationErr struct {
+	Code   string       `json:"code"`
+	Fields []FieldError `json:"fields"`
+}
+
 type (
-	BadRequestErr     BaseError
-	ForbiddenErr      BaseError
-	NotFoundErr       BaseError
-	NotAllowedErr     BaseError
-	NoContentErr      BaseError
-	TooManyRequestErr BaseError
-	AuthorizationErr  BaseError
-	ConflictErr       BaseError
+	BadRequestErr           BaseError
+	ForbiddenErr            BaseError
+	NotFoundErr             BaseError
+	NotAllowedErr           BaseError
+	NoContentErr            BaseError
+	TooManyRequestErr       BaseError
+	AuthorizationErr        BaseError
+	ConflictErr             BaseError
+	UnsupportedMediaTypeErr BaseError
+	PreconditionFailedErr   BaseError
+	ServiceUnavailableErr   BaseError
+	GoneErr                 BaseError
 )
 
 func (e BaseError) Error() string {
 	return e.Code
 }
 
+func (e ValidationErr) Error() string {
+	return e.Code
+}
+
 func (e BadRequestErr) Error() string {
 	return e.Code
 }
@@ -53,3 +73,19 @@ func (e AuthorizationErr) Error() string {
 func (e ConflictErr) Error() string {
 	return e.Code
 }
+
+func (e UnsupportedMediaTypeErr) Error() string {
+	return e.Code
+}
+
+func (e PreconditionFailedErr) Error() string {
+	return e.Code
+}
+
+func (e ServiceUnavailableErr) Error() string {
+	return e.Code
+}
+
+func (e GoneErr) Error() string {
+	return e.Code
+}
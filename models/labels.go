@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Label struct {
+	PK        int       `db:"pk"`
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type DisplayLabel struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (l *Label) Parse() *DisplayLabel {
+	return &DisplayLabel{
+		ID:        l.ID,
+		Name:      l.Name,
+		CreatedAt: l.CreatedAt.UTC(),
+		UpdatedAt: l.UpdatedAt.UTC(),
+	}
+}
+
+// CreateLabelParams creates a label, its name is unique and can't contain commas
+// since labels are filtered by a comma-separated list of names
+type CreateLabelParams struct {
+	Name string `json:"name" binding:"required,max=50,excludesall=0x2C"`
+}
+
+type CreateLabelResp struct {
+	Result *DisplayLabel `json:"result"`
+}
+
+type GetLabelResp struct {
+	Result *DisplayLabel `json:"result"`
+}
+
+type ListLabelResp struct {
+	Result []*DisplayLabel `json:"result"`
+}
+
+type PutLabelParams struct {
+	Name string `json:"name" binding:"required,max=50,excludesall=0x2C"`
+}
+
+type PutLabelResp struct {
+	Result *DisplayLabel `json:"result"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// List is a board of tasks, e.g. a project. Tasks can't be added to an archived list.
+type List struct {
+	PK         int         `db:"pk"`
+	ID         uuid.UUID   `db:"id"`
+	Name       string      `db:"name"`
+	CreatedAt  time.Time   `db:"created_at"`
+	UpdatedAt  time.Time   `db:"updated_at"`
+	ArchivedAt pq.NullTime `db:"archived_at"`
+}
+
+type DisplayList struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	Archived   bool       `json:"archived"`
+}
+
+func (l *List) Parse() *DisplayList {
+	return &DisplayList{
+		ID:         l.ID,
+		Name:       l.Name,
+		CreatedAt:  l.CreatedAt.UTC(),
+		UpdatedAt:  l.UpdatedAt.UTC(),
+		ArchivedAt: parseNullTime(l.ArchivedAt),
+		Archived:   l.ArchivedAt.Valid,
+	}
+}
+
+type CreateListParams struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+type CreateListResp struct {
+	Result *DisplayList `json:"result"`
+}
+
+type GetListResp struct {
+	Result *DisplayList `json:"result"`
+}
+
+type ListListParams struct {
+	WithArchived bool `form:"with_archived"`
+}
+
+type ListListResp struct {
+	Result []*DisplayList `json:"result"`
+}
+
+type PutListParams struct {
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+type PutListResp struct {
+	Result *DisplayList `json:"result"`
+}
+
+type ArchiveListResp struct {
+	Result *DisplayList `json:"result"`
+}
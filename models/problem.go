@@ -0,0 +1,42 @@
+package models
+
+import (
+	"net/http"
+	"strings"
+)
+
+const MIMEProblemJSON = "application/problem+json"
+
+// Problem is the problem details for HTTP APIs defined in RFC 7807,
+// Code and Fields are the extension members carrying what BaseError and ValidationErr have
+// swagger:model problem
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Fields    []FieldError `json:"fields,omitempty"`
+}
+
+// NewProblem describes err, which must be one of the errors in models, responded with status
+func NewProblem(status int, err error, instance, requestID string) *Problem {
+	code := err.Error()
+	p := &Problem{
+		// NOTE: "about:blank" tells the problem has no semantics beyond the status code,
+		// clients distinguish problems by code instead
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    strings.ToLower(strings.ReplaceAll(code, "_", " ")),
+		Instance:  instance,
+		Code:      code,
+		RequestID: requestID,
+	}
+	if ve, ok := err.(ValidationErr); ok {
+		p.Fields = ve.Fields
+	}
+	return p
+}
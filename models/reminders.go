@@ -0,0 +1,123 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type ReminderStatus string
+
+const (
+	ReminderStatusPending ReminderStatus = "pending"
+	ReminderStatusSent    ReminderStatus = "sent"
+	ReminderStatusFailed  ReminderStatus = "failed"
+)
+
+// Reminder notifies subscribers of a task at RemindAt, or OffsetMinutes after the due date of the task
+type Reminder struct {
+	PK            int           `db:"pk"`
+	ID            uuid.UUID     `db:"id"`
+	TaskID        uuid.UUID     `db:"task_id"`
+	RemindAt      pq.NullTime   `db:"remind_at"`
+	OffsetMinutes sql.NullInt32 `db:"offset_minutes"`
+	// FireAt is when the reminder fires, it's NULL if it's relative and the task has no due date
+	FireAt        pq.NullTime    `db:"fire_at"`
+	Attempts      int            `db:"attempts"`
+	NextAttemptAt pq.NullTime    `db:"next_attempt_at"`
+	SentAt        pq.NullTime    `db:"sent_at"`
+	FailedAt      pq.NullTime    `db:"failed_at"`
+	LastError     sql.NullString `db:"last_error"`
+	CreatedAt     time.Time      `db:"created_at"`
+}
+
+type DisplayReminder struct {
+	ID       uuid.UUID  `json:"id"`
+	TaskID   uuid.UUID  `json:"task_id"`
+	RemindAt *time.Time `json:"remind_at,omitempty"`
+	// OffsetMinutes is relative to the due date of the task, negative ones are before it
+	OffsetMinutes *int           `json:"offset_minutes,omitempty"`
+	FireAt        *time.Time     `json:"fire_at,omitempty"`
+	Status        ReminderStatus `json:"status"`
+	Attempts      int            `json:"attempts"`
+	SentAt        *time.Time     `json:"sent_at,omitempty"`
+	LastError     string         `json:"last_error,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+func (r *Reminder) Status() ReminderStatus {
+	switch {
+	case r.SentAt.Valid:
+		return ReminderStatusSent
+	case r.FailedAt.Valid:
+		return ReminderStatusFailed
+	default:
+		return ReminderStatusPending
+	}
+}
+
+func (r *Reminder) Parse() *DisplayReminder {
+	dr := &DisplayReminder{
+		ID:        r.ID,
+		TaskID:    r.TaskID,
+		RemindAt:  parseNullTime(r.RemindAt),
+		FireAt:    parseNullTime(r.FireAt),
+		Status:    r.Status(),
+		Attempts:  r.Attempts,
+		SentAt:    parseNullTime(r.SentAt),
+		LastError: r.LastError.String,
+		CreatedAt: r.CreatedAt.UTC(),
+	}
+	if r.OffsetMinutes.Valid {
+		offset := int(r.OffsetMinutes.Int32)
+		dr.OffsetMinutes = &offset
+	}
+	return dr
+}
+
+// DueReminder is a reminder claimed to be sent along with its task
+type DueReminder struct {
+	Reminder
+	TaskName  string      `db:"task_name"`
+	TaskDueAt pq.NullTime `db:"task_due_at"`
+}
+
+// ReminderEventData is the data of task.reminder webhook events
+type ReminderEventData struct {
+	Reminder *DisplayReminder `json:"reminder"`
+	Task     *ReminderTask    `json:"task"`
+}
+
+type ReminderTask struct {
+	ID    uuid.UUID  `json:"id"`
+	Name  string     `json:"name"`
+	DueAt *time.Time `json:"due_at,omitempty"`
+}
+
+func (r *DueReminder) EventData() *ReminderEventData {
+	return &ReminderEventData{
+		Reminder: r.Reminder.Parse(),
+		Task: &ReminderTask{
+			ID:    r.TaskID,
+			Name:  r.TaskName,
+			DueAt: parseNullTime(r.TaskDueAt),
+		},
+	}
+}
+
+// CreateReminderParams takes either RemindAt or OffsetMinutes
+type CreateReminderParams struct {
+	RemindAt *time.Time `json:"remind_at" binding:"required_without=OffsetMinutes,excluded_with=OffsetMinutes"`
+	// OffsetMinutes is relative to the due date of the task, negative ones are before it. The task must have a due date.
+	OffsetMinutes *int `json:"offset_minutes" binding:"omitempty,min=-525600,max=525600"`
+}
+
+type CreateReminderResp struct {
+	Result *DisplayReminder `json:"result"`
+}
+
+type ListReminderResp struct {
+	Result []*DisplayReminder `json:"result"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+type TaskStatus string
+
+const (
+	TaskStatusTodo       TaskStatus = "todo"
+	TaskStatusInProgress TaskStatus = "in_progress"
+	TaskStatusDone       TaskStatus = "done"
+	TaskStatusArchived   TaskStatus = "archived"
+)
+
+// legacyTaskStatuses maps the integers which status used to be to TaskStatus
+var legacyTaskStatuses = map[string]TaskStatus{
+	"0": TaskStatusTodo,
+	"1": TaskStatusDone,
+}
+
+// Normalize converts the legacy integer status to TaskStatus, others are returned as is
+func (s TaskStatus) Normalize() TaskStatus {
+	if ts, ok := legacyTaskStatuses[string(s)]; ok {
+		return ts
+	}
+	return s
+}
+
+// UnmarshalJSON accepts both the name of status and the legacy integer.
+// Unknown values are kept so that validation reports them.
+func (s *TaskStatus) UnmarshalJSON(b []byte) error {
+	var i int
+	if err := json.Unmarshal(b, &i); err == nil {
+		*s = TaskStatus(strconv.Itoa(i)).Normalize()
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	*s = TaskStatus(str).Normalize()
+	return nil
+}
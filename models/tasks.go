@@ -1,6 +1,8 @@
 package models
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,40 +11,259 @@ import (
 
 type Task struct {
 	// TODO:
-	PK        int         `db:"pk"`
-	ID        uuid.UUID   `db:"id"`
-	Name      string      `db:"name"`
-	Status    int         `db:"status"`
-	CreatedAt time.Time   `db:"created_at"`
-	UpdatedAt time.Time   `db:"updated_at"`
-	DeletedAt pq.NullTime `db:"deleted_at"`
+	PK          int           `db:"pk"`
+	ID          uuid.UUID     `db:"id"`
+	ParentID    uuid.NullUUID `db:"parent_id"`
+	ListID      uuid.NullUUID `db:"list_id"`
+	Name        string        `db:"name"`
+	Description string        `db:"description"`
+	Status      TaskStatus    `db:"status"`
+	Priority    int           `db:"priority"`
+	DueAt       pq.NullTime   `db:"due_at"`
+	CompletedAt pq.NullTime   `db:"completed_at"`
+	CreatedAt   time.Time     `db:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at"`
+	DeletedAt   pq.NullTime   `db:"deleted_at"`
+	Version     int           `db:"version"`
+	// Position is the lexorank key ordering the task among the others
+	Position string `db:"position"`
+	// RRule is the recurrence rule of the task, SeriesID is the ID of the first occurrence, which is NULL
+	// until the task recurs, and Occurrence is the 1-based index of the task in the series
+	RRule      sql.NullString `db:"rrule"`
+	SeriesID   uuid.NullUUID  `db:"series_id"`
+	Occurrence int            `db:"occurrence"`
+	// Labels is the names of labels attached to the task
+	Labels pq.StringArray `db:"labels"`
+	// SubtaskCount is the number of subtasks which are neither deleted nor archived,
+	// SubtaskDoneCount is how many of them are done
+	SubtaskCount     int `db:"subtask_count"`
+	SubtaskDoneCount int `db:"subtask_done_count"`
+	// OpenBlockerCount is the number of tasks blocking the task which are neither done, archived nor deleted
+	OpenBlockerCount int `db:"open_blocker_count"`
+}
+
+// TaskProgress is how many subtasks of the task are done, archived subtasks are left out
+type TaskProgress struct {
+	Done    int `json:"done"`
+	Total   int `json:"total"`
+	Percent int `json:"percent"`
 }
 
 type DisplayTask struct {
-	ID     uuid.UUID `json:"id"`
-	Name   string    `json:"name"`
-	Status int       `json:"status"`
+	ID uuid.UUID `json:"id"`
+	// ParentID is the task this task is a subtask of, it's absent for top-level tasks
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+	// ListID is the list the task belongs to, it's absent for tasks in no list
+	ListID *uuid.UUID `json:"list_id,omitempty"`
+	// Name is the title of the task, Description is its details in markdown
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Status      TaskStatus `json:"status"`
+	// Priority is from 0, the lowest, to 3
+	Priority int        `json:"priority"`
+	DueAt    *time.Time `json:"due_at,omitempty"`
+	// CompletedAt is when the task became done, it's absent unless the task is done
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	Deleted     bool       `json:"deleted"`
+	Version     int        `json:"version"`
+	// Position orders tasks manually, tasks are listed in ascending byte order of it by default
+	Position string `json:"position"`
+	// RRule is the RFC 5545 recurrence rule, SeriesID and Occurrence are absent unless the task recurs
+	RRule      string     `json:"rrule,omitempty"`
+	SeriesID   *uuid.UUID `json:"series_id,omitempty"`
+	Occurrence int        `json:"occurrence,omitempty"`
+	Labels     []string   `json:"labels"`
+	// Progress is absent if the task has no subtask
+	Progress *TaskProgress `json:"progress,omitempty"`
+	// Blocked tells whether the task has open blockers, which keeps it from being done
+	Blocked bool `json:"blocked"`
 }
 
+// Parse converts the task to what clients see, timestamps are in UTC and marshaled in RFC 3339
 func (t *Task) Parse() *DisplayTask {
-	return &DisplayTask{
-		ID:     t.ID,
-		Name:   t.Name,
-		Status: t.Status,
+	labels := []string(t.Labels)
+	if labels == nil {
+		labels = []string{}
+	}
+	dt := &DisplayTask{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		DueAt:       parseNullTime(t.DueAt),
+		CompletedAt: parseNullTime(t.CompletedAt),
+		CreatedAt:   t.CreatedAt.UTC(),
+		UpdatedAt:   t.UpdatedAt.UTC(),
+		DeletedAt:   parseNullTime(t.DeletedAt),
+		Deleted:     t.DeletedAt.Valid,
+		Version:     t.Version,
+		Position:    t.Position,
+		RRule:       t.RRule.String,
+		Labels:      labels,
+		Blocked:     t.OpenBlockerCount > 0,
+	}
+	if t.ParentID.Valid {
+		parentID := t.ParentID.UUID
+		dt.ParentID = &parentID
+	}
+	if t.RRule.Valid || t.SeriesID.Valid {
+		seriesID := t.ID
+		if t.SeriesID.Valid {
+			seriesID = t.SeriesID.UUID
+		}
+		dt.SeriesID = &seriesID
+		dt.Occurrence = t.Occurrence
+	}
+	if t.ListID.Valid {
+		listID := t.ListID.UUID
+		dt.ListID = &listID
+	}
+	if t.SubtaskCount > 0 {
+		dt.Progress = &TaskProgress{
+			Done:    t.SubtaskDoneCount,
+			Total:   t.SubtaskCount,
+			Percent: t.SubtaskDoneCount * 100 / t.SubtaskCount,
+		}
+	}
+	return dt
+}
+
+func parseNullTime(t pq.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
+// NullTime converts an optional time to its column value in UTC, nil is NULL
+func NullTime(t *time.Time) pq.NullTime {
+	if t == nil {
+		return pq.NullTime{}
+	}
+	return pq.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// NullUUID converts an optional ID to its column value, nil is NULL
+func NullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
 	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
 }
 
+type GetTaskParams struct {
+	WithDeleted bool `form:"with_deleted"`
+}
+
+type GetTaskResp struct {
+	Result *DisplayTask `json:"result"`
+}
+
+// PutTaskParams replaces the whole task, omitted fields are reset to their defaults
 type PutTaskParams struct {
-	Name   string `json:"name"`
-	Status int    `json:"status"`
+	Name        string `json:"name" binding:"required,max=50"`
+	Description string `json:"description" binding:"max=10000"`
+	// Status defaults to todo, the legacy 0 and 1 are accepted as todo and done
+	Status   TaskStatus `json:"status" binding:"omitempty,oneof=todo in_progress done archived"`
+	Priority int        `json:"priority" binding:"min=0,max=3"`
+	DueAt    *time.Time `json:"due_at"`
+	// Labels replaces the labels attached to the task, omitted means none
+	Labels []string `json:"labels" binding:"max=20,dive,min=1,max=50"`
+	// ParentID moves the task under another task, omitted means top-level
+	ParentID *uuid.UUID `json:"parent_id"`
+	// ListID moves the task to another list, omitted means no list
+	ListID *uuid.UUID `json:"list_id"`
+	// RRule repeats the task by the RFC 5545 recurrence rule, omitted means no repetition
+	RRule string `json:"rrule" binding:"max=500"`
 }
 
-type ListTaskResp struct {
+// PatchTaskParams holds the fields to update, nil fields are left untouched
+type PatchTaskParams struct {
+	Name        *string     `json:"name" binding:"omitempty,min=1,max=50"`
+	Description *string     `json:"description" binding:"omitempty,max=10000"`
+	Status      *TaskStatus `json:"status" binding:"omitempty,oneof=todo in_progress done archived"`
+	Priority    *int        `json:"priority" binding:"omitempty,min=0,max=3"`
+	// DueAt is removed by null in merge patch, or remove operation in JSON Patch
+	DueAt      *time.Time `json:"due_at"`
+	ClearDueAt bool       `json:"-"`
+	// Labels replaces the labels attached to the task, null or empty array detaches all of them
+	Labels []string `json:"labels" binding:"omitempty,max=20,dive,min=1,max=50"`
+	// ParentID moves the task under another task, null moves it to the top level
+	ParentID      *uuid.UUID `json:"parent_id"`
+	ClearParentID bool       `json:"-"`
+	// ListID moves the task to another list, null moves it out of any list
+	ListID      *uuid.UUID `json:"list_id"`
+	ClearListID bool       `json:"-"`
+	// RRule repeats the task by the RFC 5545 recurrence rule, null or empty string stops the repetition
+	RRule      *string `json:"rrule" binding:"omitempty,max=500"`
+	ClearRRule bool    `json:"-"`
+}
+
+type PatchTaskResp struct {
+	Result *DisplayTask `json:"result"`
+}
+
+type ListTaskParams struct {
+	Limit  int        `form:"limit" binding:"min=0,max=100"`
+	Cursor string     `form:"cursor"`
+	Status TaskStatus `form:"status" binding:"omitempty,oneof=todo in_progress done archived 0 1"`
+	Name   string     `form:"name"`
+	Q      string     `form:"q" binding:"max=200"`
+	// Order lists tasks after their blockers when it's dependency, it's a shorthand of sort=dependency
+	Order string `form:"order" binding:"omitempty,oneof=dependency"`
+	// Label is comma-separated names of labels, tasks having any or all of them are listed by LabelMatch
+	Label      string `form:"label"`
+	LabelMatch string `form:"label_match" binding:"omitempty,oneof=any all"`
+	// Priority filters tasks with exactly the priority
+	Priority *int `form:"priority" binding:"omitempty,min=0,max=3"`
+	// DueAfter and DueBefore filter tasks due in [DueAfter, DueBefore)
+	DueAfter  time.Time `form:"due_after"`
+	DueBefore time.Time `form:"due_before"`
+	Sort      string    `form:"sort"`
+}
+
+// MoveTaskParams places the task right after AfterID or right before BeforeID, or between both of them
+type MoveTaskParams struct {
+	AfterID  *uuid.UUID `json:"after_id" binding:"required_without=BeforeID"`
+	BeforeID *uuid.UUID `json:"before_id"`
+}
+
+type MoveTaskResp struct {
+	Result *DisplayTask `json:"result"`
+}
+
+type AddBlockerParams struct {
+	BlockerID uuid.UUID `json:"blocker_id" binding:"required"`
+}
+
+type ListBlockerResp struct {
 	Result []*DisplayTask `json:"result"`
 }
 
+type ListTaskResp struct {
+	Result     []*DisplayTask `json:"result"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
 type CreateTaskParams struct {
-	Name string `json:"name"`
+	Name        string     `json:"name" binding:"required,max=50"`
+	Description string     `json:"description" binding:"max=10000"`
+	Priority    int        `json:"priority" binding:"min=0,max=3"`
+	DueAt       *time.Time `json:"due_at"`
+	// Labels is the names of existing labels to attach
+	Labels []string `json:"labels" binding:"max=20,dive,min=1,max=50"`
+	// ParentID creates the task as a subtask of the task
+	ParentID *uuid.UUID `json:"parent_id"`
+	// ListID creates the task in the list, which mustn't be archived
+	ListID *uuid.UUID `json:"list_id"`
+	// RRule repeats the task by the RFC 5545 recurrence rule, e.g. FREQ=WEEKLY;BYDAY=MO. due_at is required
+	// with it and is the first occurrence. The next occurrence is made once the task is done or due.
+	RRule string `json:"rrule" binding:"max=500"`
 }
 
 type CreateTaskResp struct {
@@ -52,3 +273,87 @@ type CreateTaskResp struct {
 type PutTaskResp struct {
 	Result *DisplayTask `json:"result"`
 }
+
+type DeleteTaskParams struct {
+	Hard bool `form:"hard"`
+}
+
+type RestoreTaskResp struct {
+	Result *DisplayTask `json:"result"`
+}
+
+type BatchOp string
+
+const (
+	BatchOpCreate BatchOp = "create"
+	BatchOpUpdate BatchOp = "update"
+	BatchOpDelete BatchOp = "delete"
+)
+
+// BatchTaskOperation creates a task with name, updates the non-nil fields of the task with ID,
+// or deletes the task with ID. Version is the optional precondition of update and delete.
+// Like merge patch, null due_at, labels, parent_id, list_id and rrule are removed by update.
+type BatchTaskOperation struct {
+	Op            BatchOp     `json:"op" binding:"required,oneof=create update delete"`
+	ID            string      `json:"id" binding:"required_unless=Op create"`
+	Version       *int        `json:"version"`
+	Name          *string     `json:"name" binding:"required_if=Op create,omitempty,min=1,max=50"`
+	Description   *string     `json:"description" binding:"omitempty,max=10000"`
+	Status        *TaskStatus `json:"status" binding:"omitempty,oneof=todo in_progress done archived"`
+	Priority      *int        `json:"priority" binding:"omitempty,min=0,max=3"`
+	DueAt         *time.Time  `json:"due_at"`
+	ClearDueAt    bool        `json:"-"`
+	Labels        []string    `json:"labels" binding:"omitempty,max=20,dive,min=1,max=50"`
+	ParentID      *uuid.UUID  `json:"parent_id"`
+	ClearParentID bool        `json:"-"`
+	ListID        *uuid.UUID  `json:"list_id"`
+	ClearListID   bool        `json:"-"`
+	RRule         *string     `json:"rrule" binding:"omitempty,max=500"`
+	ClearRRule    bool        `json:"-"`
+}
+
+// UnmarshalJSON sets the Clear flags of the members which are null
+func (o *BatchTaskOperation) UnmarshalJSON(b []byte) error {
+	type operation BatchTaskOperation
+	op := operation{}
+	if err := json.Unmarshal(b, &op); err != nil {
+		return err
+	}
+
+	doc := map[string]json.RawMessage{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+	null := func(k string) bool {
+		v, ok := doc[k]
+		return ok && string(v) == "null"
+	}
+	op.ClearDueAt = null("due_at")
+	op.ClearParentID = null("parent_id")
+	op.ClearListID = null("list_id")
+	op.ClearRRule = null("rrule")
+	if null("labels") {
+		op.Labels = []string{}
+	}
+
+	*o = BatchTaskOperation(op)
+	return nil
+}
+
+type BatchTaskParams struct {
+	// Atomic rolls back every operation once one fails, otherwise only the failed one is
+	Atomic     bool                  `json:"atomic"`
+	Operations []*BatchTaskOperation `json:"operations" binding:"required,min=1,max=500,dive"`
+}
+
+type BatchTaskResult struct {
+	// Status is the status code the operation would get as a single request
+	Status int          `json:"status"`
+	Result *DisplayTask `json:"result,omitempty"`
+	Error  interface{}  `json:"error,omitempty"`
+}
+
+type BatchTaskResp struct {
+	Committed bool               `json:"committed"`
+	Result    []*BatchTaskResult `json:"result"`
+}
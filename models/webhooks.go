@@ -0,0 +1,216 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+	"github.com/lib/pq"
+)
+
+// task lifecycle events, the data of them is the task after the mutation. Only mutations of tasks themselves
+// emit them, adding or removing blockers and changing labels or lists don't.
+const (
+	TaskEventCreated = "task.created"
+	TaskEventUpdated = "task.updated"
+	TaskEventDeleted = "task.deleted"
+)
+
+// WebhookEvent is the body of webhooks, ID is the same among the retries of an event so that
+// receivers can drop duplicates
+type WebhookEvent struct {
+	ID        uuid.UUID   `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// TaskEvent is an event of the task in the outbox, which is the persisted log of task events.
+// PK orders the events, though an event can be committed after the ones following it.
+type TaskEvent struct {
+	PK int64 `db:"pk"`
+	// XID is the transaction writing the event
+	XID       uint64         `db:"xid"`
+	ID        uuid.UUID      `db:"id"`
+	Event     string         `db:"event"`
+	TaskID    uuid.UUID      `db:"task_id"`
+	Payload   types.JSONText `db:"payload"`
+	CreatedAt time.Time      `db:"created_at"`
+}
+
+// WebhookEvent is the body of the event, which is the same for webhooks and streams
+func (e *TaskEvent) WebhookEvent() *WebhookEvent {
+	return &WebhookEvent{
+		ID:        e.ID,
+		Event:     e.Event,
+		CreatedAt: e.CreatedAt.UTC(),
+		Data:      e.Payload,
+	}
+}
+
+// Webhook subscribes URL to the task events named in Events, or all of them if it's empty
+type Webhook struct {
+	PK        int            `db:"pk"`
+	ID        uuid.UUID      `db:"id"`
+	URL       string         `db:"url"`
+	Events    pq.StringArray `db:"events"`
+	Secret    string         `db:"secret"`
+	CreatedAt time.Time      `db:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at"`
+}
+
+type DisplayWebhook struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (w *Webhook) Parse() *DisplayWebhook {
+	events := []string(w.Events)
+	if events == nil {
+		events = []string{}
+	}
+	return &DisplayWebhook{
+		ID:        w.ID,
+		URL:       w.URL,
+		Events:    events,
+		CreatedAt: w.CreatedAt.UTC(),
+		UpdatedAt: w.UpdatedAt.UTC(),
+	}
+}
+
+// DisplayCreatedWebhook is the webhook along with its secret, which is shown only when the webhook is created
+type DisplayCreatedWebhook struct {
+	DisplayWebhook
+	// Secret signs the deliveries of the webhook, see X-Webhook-Signature
+	Secret string `json:"secret"`
+}
+
+func (w *Webhook) ParseCreated() *DisplayCreatedWebhook {
+	return &DisplayCreatedWebhook{
+		DisplayWebhook: *w.Parse(),
+		Secret:         w.Secret,
+	}
+}
+
+type CreateWebhookParams struct {
+	URL string `json:"url" binding:"required,http_url,max=2000"`
+	// Events filters the events sent to the webhook, all of them are sent if it's empty
+	Events []string `json:"events" binding:"omitempty,dive,oneof=task.created task.updated task.deleted"`
+}
+
+type CreateWebhookResp struct {
+	Result *DisplayCreatedWebhook `json:"result"`
+}
+
+type GetWebhookResp struct {
+	Result *DisplayWebhook `json:"result"`
+}
+
+type ListWebhookResp struct {
+	Result []*DisplayWebhook `json:"result"`
+}
+
+type PutWebhookParams struct {
+	URL    string   `json:"url" binding:"required,http_url,max=2000"`
+	Events []string `json:"events" binding:"omitempty,dive,oneof=task.created task.updated task.deleted"`
+}
+
+type PutWebhookResp struct {
+	Result *DisplayWebhook `json:"result"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryStatusDead is a delivery given up after too many failures
+	WebhookDeliveryStatusDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery is an event to be sent to a webhook
+type WebhookDelivery struct {
+	PK            int            `db:"pk"`
+	ID            uuid.UUID      `db:"id"`
+	WebhookID     uuid.UUID      `db:"webhook_id"`
+	EventID       uuid.UUID      `db:"event_id"`
+	Event         string         `db:"event"`
+	Attempts      int            `db:"attempts"`
+	NextAttemptAt pq.NullTime    `db:"next_attempt_at"`
+	DeliveredAt   pq.NullTime    `db:"delivered_at"`
+	DeadAt        pq.NullTime    `db:"dead_at"`
+	LastError     sql.NullString `db:"last_error"`
+	CreatedAt     time.Time      `db:"created_at"`
+}
+
+type DisplayWebhookDelivery struct {
+	ID      uuid.UUID             `json:"id"`
+	EventID uuid.UUID             `json:"event_id"`
+	Event   string                `json:"event"`
+	Status  WebhookDeliveryStatus `json:"status"`
+	// Attempts counts the times the delivery is tried, including the one in progress
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	DeadAt        *time.Time `json:"dead_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (d *WebhookDelivery) Status() WebhookDeliveryStatus {
+	switch {
+	case d.DeliveredAt.Valid:
+		return WebhookDeliveryStatusDelivered
+	case d.DeadAt.Valid:
+		return WebhookDeliveryStatusDead
+	default:
+		return WebhookDeliveryStatusPending
+	}
+}
+
+func (d *WebhookDelivery) Parse() *DisplayWebhookDelivery {
+	return &DisplayWebhookDelivery{
+		ID:            d.ID,
+		EventID:       d.EventID,
+		Event:         d.Event,
+		Status:        d.Status(),
+		Attempts:      d.Attempts,
+		NextAttemptAt: parseNullTime(d.NextAttemptAt),
+		DeliveredAt:   parseNullTime(d.DeliveredAt),
+		DeadAt:        parseNullTime(d.DeadAt),
+		LastError:     d.LastError.String,
+		CreatedAt:     d.CreatedAt.UTC(),
+	}
+}
+
+// DueWebhookDelivery is a delivery claimed to be sent along with its webhook and event
+type DueWebhookDelivery struct {
+	WebhookDelivery
+	URL            string         `db:"url"`
+	Secret         string         `db:"secret"`
+	Payload        types.JSONText `db:"payload"`
+	EventCreatedAt time.Time      `db:"event_created_at"`
+}
+
+// WebhookEvent is the body sent for the delivery, which is the same among the retries
+func (d *DueWebhookDelivery) WebhookEvent() *WebhookEvent {
+	return &WebhookEvent{
+		ID:        d.EventID,
+		Event:     d.Event,
+		CreatedAt: d.EventCreatedAt.UTC(),
+		Data:      d.Payload,
+	}
+}
+
+type ListWebhookDeliveryParams struct {
+	Status WebhookDeliveryStatus `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+	Limit  int                   `form:"limit" binding:"min=0,max=100"`
+}
+
+type ListWebhookDeliveryResp struct {
+	Result []*DisplayWebhookDelivery `json:"result"`
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/lib/pq"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+var (
+	ErrDuplicate       = models.ConflictErr{Code: "DUPLICATE_RESOURCE"}
+	ErrReferenced      = models.ConflictErr{Code: "RESOURCE_REFERENCED"}
+	ErrConstraint      = models.BadRequestErr{Code: "CONSTRAINT_VIOLATION"}
+	ErrValueTooLong    = models.BadRequestErr{Code: "VALUE_TOO_LONG"}
+	ErrInvalidValue    = models.BadRequestErr{Code: "INVALID_VALUE"}
+	ErrUnavailable     = models.ServiceUnavailableErr{Code: "DATABASE_UNAVAILABLE"}
+	ErrQueryCanceled   = models.ServiceUnavailableErr{Code: "DATABASE_TIMEOUT"}
+	errorCodeTranslate = map[pq.ErrorCode]error{
+		"23505": ErrDuplicate,    // unique_violation
+		"23503": ErrReferenced,   // foreign_key_violation
+		"23514": ErrConstraint,   // check_violation
+		"23502": ErrConstraint,   // not_null_violation
+		"22001": ErrValueTooLong, // string_data_right_truncation
+		"22003": ErrInvalidValue, // numeric_value_out_of_range
+		"22007": ErrInvalidValue, // invalid_datetime_format
+		"22008": ErrInvalidValue, // datetime_field_overflow
+		"22P02": ErrInvalidValue, // invalid_text_representation
+		"57014": ErrQueryCanceled,
+	}
+	errorClassTranslate = map[pq.ErrorClass]error{
+		"08": ErrUnavailable, // connection_exception
+		"53": ErrUnavailable, // insufficient_resources
+		"57": ErrUnavailable, // operator_intervention, e.g. admin_shutdown
+	}
+)
+
+// IsDuplicate tells whether err is a unique violation
+func IsDuplicate(err error) bool {
+	return TranslateError(err) == ErrDuplicate
+}
+
+// TranslateError maps errors of database to models errors which are safe to show to clients,
+// errors it doesn't recognize are returned as is.
+func TranslateError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if e, ok := errorCodeTranslate[pqErr.Code]; ok {
+			return e
+		}
+		if e, ok := errorClassTranslate[pqErr.Code.Class()]; ok {
+			return e
+		}
+		return err
+	}
+
+	// NOTE: the query is canceled by its deadline or the client going away
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return ErrQueryCanceled
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr) {
+		return ErrUnavailable
+	}
+	return err
+}
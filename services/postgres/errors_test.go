@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateError(t *testing.T) {
+	unknown := errors.New("mock-error")
+	unknownPQ := &pq.Error{Code: "42P01"}
+
+	tests := []struct {
+		desc   string
+		err    error
+		expErr error
+	}{
+		{
+			desc:   "unique violation",
+			err:    &pq.Error{Code: "23505", Constraint: "tasks_id_idx"},
+			expErr: ErrDuplicate,
+		},
+		{
+			desc:   "check violation",
+			err:    &pq.Error{Code: "23514"},
+			expErr: ErrConstraint,
+		},
+		{
+			desc:   "string truncation",
+			err:    &pq.Error{Code: "22001"},
+			expErr: ErrValueTooLong,
+		},
+		{
+			desc:   "wrapped pq error",
+			err:    fmt.Errorf("insert failed: %w", &pq.Error{Code: "23505"}),
+			expErr: ErrDuplicate,
+		},
+		{
+			desc:   "connection exception class",
+			err:    &pq.Error{Code: "08006"},
+			expErr: ErrUnavailable,
+		},
+		{
+			desc:   "bad connection",
+			err:    driver.ErrBadConn,
+			expErr: ErrUnavailable,
+		},
+		{
+			desc:   "connection done",
+			err:    sql.ErrConnDone,
+			expErr: ErrUnavailable,
+		},
+		{
+			desc:   "network error",
+			err:    &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+			expErr: ErrUnavailable,
+		},
+		{
+			desc:   "query deadline exceeded",
+			err:    fmt.Errorf("select failed: %w", context.DeadlineExceeded),
+			expErr: ErrQueryCanceled,
+		},
+		{
+			desc:   "query canceled by client",
+			err:    context.Canceled,
+			expErr: ErrQueryCanceled,
+		},
+		{
+			desc:   "unknown pq error",
+			err:    unknownPQ,
+			expErr: unknownPQ,
+		},
+		{
+			desc:   "unknown error",
+			err:    unknown,
+			expErr: unknown,
+		},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expErr, TranslateError(test.err), test.desc)
+	}
+}
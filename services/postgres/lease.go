@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lease finishes the rows claimed for a while. A claim is the id of a row along with its attempts, which count up
+// every claim, so that a row claimed again after the lease passes can't be finished by the claim before.
+type Lease struct {
+	// Table has the claimed rows, which have id, attempts, next_attempt_at and last_error
+	Table string
+	// DoneColumn is when the row succeeded
+	DoneColumn string
+	// FailedColumn is when the row is given up
+	FailedColumn string
+	// ErrLost is returned when the row has been claimed again or finished since
+	ErrLost error
+}
+
+// MarkDone marks the claimed row succeeded
+func (l *Lease) MarkDone(ctx context.Context, qr *Querier, id uuid.UUID, attempts int, now time.Time) error {
+	s := "UPDATE " + l.Table + " SET " + l.DoneColumn + "=$1, next_attempt_at=NULL, last_error=NULL WHERE " + l.claimedConds()
+	return l.held(qr.Exec(ctx, s, now, id, attempts))
+}
+
+// MarkFailed records the failure of the claimed row, which is retried after retryAt or given up if retryAt is zero
+func (l *Lease) MarkFailed(ctx context.Context, qr *Querier, id uuid.UUID, attempts int, reason string, now, retryAt time.Time) error {
+	if retryAt.IsZero() {
+		s := "UPDATE " + l.Table + " SET " + l.FailedColumn + "=$1, next_attempt_at=NULL, last_error=$4 WHERE " + l.claimedConds()
+		return l.held(qr.Exec(ctx, s, now, id, attempts, reason))
+	}
+	s := "UPDATE " + l.Table + " SET next_attempt_at=$1, last_error=$4 WHERE " + l.claimedConds()
+	return l.held(qr.Exec(ctx, s, retryAt, id, attempts, reason))
+}
+
+// claimedConds are where the row $2 is still claimed with attempts $3, no one claimed it again or finished it
+func (l *Lease) claimedConds() string {
+	return "id=$2 AND attempts=$3 AND " + l.DoneColumn + " IS NULL AND " + l.FailedColumn + " IS NULL"
+}
+
+// held returns ErrLost if the update of claimedConds matches no row
+func (l *Lease) held(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return l.ErrLost
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+const (
+	listenerMinReconnect = 10 * time.Second
+	listenerMaxReconnect = time.Minute
+	// listenerPingInterval checks the connection while no notification comes, so that a broken one is reconnected
+	listenerPingInterval = 90 * time.Second
+	// subscriptionBuffer is how many payloads a subscriber can fall behind before it's dropped
+	subscriptionBuffer = 64
+)
+
+// Listener listens to a channel on a dedicated connection and broadcasts the payloads of its notifications
+// to subscribers. Notifications can't be replayed, so a subscription is closed whenever some of them may be
+// lost, e.g. the subscriber falls behind or the connection is reestablished, and the subscriber is expected to
+// catch up from where the payloads are persisted.
+type Listener struct {
+	uri     string
+	channel string
+
+	mu     sync.Mutex
+	subs   map[chan string]struct{}
+	closed bool
+}
+
+func NewListener(uri, channel string) *Listener {
+	return &Listener{
+		uri:     uri,
+		channel: channel,
+		subs:    map[chan string]struct{}{},
+	}
+}
+
+// Subscribe returns the channel of payloads and the function ending the subscription, which must be called
+// once the subscriber is done. The channel is closed at once if the listener has stopped.
+func (l *Listener) Subscribe() (<-chan string, func()) {
+	ch := make(chan string, subscriptionBuffer)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	l.subs[ch] = struct{}{}
+
+	return ch, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if _, ok := l.subs[ch]; ok {
+			delete(l.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Listen broadcasts notifications until ctx is done, then closes every subscription
+func (l *Listener) Listen(ctx context.Context) error {
+	defer l.close()
+
+	listener := pq.NewListener(l.uri, listenerMinReconnect, listenerMaxReconnect, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("channel", l.channel).Msg("pq.Listener failed")
+		}
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		// NOTE: closing the listener unblocks Listen waiting for the connection and ends Notify
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		}
+		listener.Close()
+	}()
+
+	if err := listener.Listen(l.channel); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case n, ok := <-listener.Notify:
+			if !ok {
+				return nil
+			}
+			// NOTE: a nil notification tells the connection is reestablished, notifications in between are lost
+			if n == nil {
+				l.dropAll()
+				continue
+			}
+			l.broadcast(n.Extra)
+		case <-ticker.C:
+			if err := listener.Ping(); err != nil {
+				zerolog.Ctx(ctx).Warn().Err(err).Str("channel", l.channel).Msg("listener.Ping failed")
+			}
+		}
+	}
+}
+
+func (l *Listener) broadcast(payload string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ch := range l.subs {
+		select {
+		case ch <- payload:
+		default:
+			delete(l.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// dropAll closes every subscription, the listener keeps taking new ones
+func (l *Listener) dropAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.drop()
+}
+
+// close closes every subscription and refuses new ones
+func (l *Listener) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.drop()
+	l.closed = true
+}
+
+// drop closes every subscription, l.mu must be held
+func (l *Listener) drop() {
+	for ch := range l.subs {
+		delete(l.subs, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenerBroadcast(t *testing.T) {
+	l := NewListener("mock-uri", "mock-channel")
+	ch1, unsubscribe1 := l.Subscribe()
+	ch2, unsubscribe2 := l.Subscribe()
+	defer unsubscribe2()
+
+	l.broadcast("1")
+	require.Equal(t, "1", <-ch1)
+	require.Equal(t, "1", <-ch2)
+
+	// unsubscribed ones get nothing more, and unsubscribing again is a no-op
+	unsubscribe1()
+	unsubscribe1()
+	_, ok := <-ch1
+	require.False(t, ok)
+	l.broadcast("2")
+	require.Equal(t, "2", <-ch2)
+}
+
+func TestListenerDropSlowSubscriber(t *testing.T) {
+	l := NewListener("mock-uri", "mock-channel")
+	slow, unsubscribe := l.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		l.broadcast("1")
+	}
+	n := 0
+	for range slow {
+		n++
+	}
+	require.Equal(t, subscriptionBuffer, n)
+}
+
+func TestListenerClose(t *testing.T) {
+	l := NewListener("mock-uri", "mock-channel")
+	ch, unsubscribe := l.Subscribe()
+	defer unsubscribe()
+
+	l.dropAll()
+	_, ok := <-ch
+	require.False(t, ok)
+	ch, unsubscribe = l.Subscribe()
+	defer unsubscribe()
+	l.broadcast("1")
+	require.Equal(t, "1", <-ch)
+
+	l.close()
+	_, ok = <-ch
+	require.False(t, ok)
+	ch, _ = l.Subscribe()
+	_, ok = <-ch
+	require.False(t, ok)
+}
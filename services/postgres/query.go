@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+// QueryOption bounds and logs the queries of a store
+type QueryOption struct {
+	// Timeout cancels a query running longer than it, 0 means no timeout
+	Timeout time.Duration
+	// SlowThreshold logs a query running longer than it, 0 disables the log
+	SlowThreshold time.Duration
+}
+
+type QueryOptionFunc func(*QueryOption)
+
+func WithQueryTimeout(d time.Duration) QueryOptionFunc {
+	return func(o *QueryOption) {
+		o.Timeout = d
+	}
+}
+
+func WithSlowQueryThreshold(d time.Duration) QueryOptionFunc {
+	return func(o *QueryOption) {
+		o.SlowThreshold = d
+	}
+}
+
+// Querier runs queries on a database or transaction, canceling the ones running longer than the timeout
+// and logging the slow ones
+type Querier struct {
+	q   sqlx.ExtContext
+	opt QueryOption
+}
+
+func NewQuerier(q sqlx.ExtContext, opts ...QueryOptionFunc) *Querier {
+	opt := QueryOption{}
+	for _, f := range opts {
+		f(&opt)
+	}
+	return &Querier{
+		q:   q,
+		opt: opt,
+	}
+}
+
+// Bind returns a querier running queries on q with the same options, e.g. on a transaction
+func (qr *Querier) Bind(q sqlx.ExtContext) *Querier {
+	return &Querier{
+		q:   q,
+		opt: qr.opt,
+	}
+}
+
+func (qr *Querier) Get(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	qctx, cancel := qr.queryContext(ctx)
+	defer cancel()
+	defer qr.logSlow(ctx, query, time.Now())
+	return sqlx.GetContext(qctx, qr.q, dest, query, args...)
+}
+
+func (qr *Querier) Select(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	qctx, cancel := qr.queryContext(ctx)
+	defer cancel()
+	defer qr.logSlow(ctx, query, time.Now())
+	return sqlx.SelectContext(qctx, qr.q, dest, query, args...)
+}
+
+func (qr *Querier) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	qctx, cancel := qr.queryContext(ctx)
+	defer cancel()
+	defer qr.logSlow(ctx, query, time.Now())
+	return qr.q.ExecContext(qctx, query, args...)
+}
+
+func (qr *Querier) NamedExec(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	qctx, cancel := qr.queryContext(ctx)
+	defer cancel()
+	defer qr.logSlow(ctx, query, time.Now())
+	return sqlx.NamedExecContext(qctx, qr.q, query, arg)
+}
+
+// queryContext bounds the query by the timeout
+func (qr *Querier) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if qr.opt.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, qr.opt.Timeout)
+}
+
+// logSlow logs the query if it took longer than the threshold
+func (qr *Querier) logSlow(ctx context.Context, query string, start time.Time) {
+	elapsed := time.Since(start)
+	if qr.opt.SlowThreshold <= 0 || elapsed < qr.opt.SlowThreshold {
+		return
+	}
+	zerolog.Ctx(ctx).Warn().Dur("elapsed", elapsed).Str("query", query).Msg("slow query")
+}
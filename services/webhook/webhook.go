@@ -0,0 +1,231 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+const (
+	HeaderID        = "X-Webhook-ID"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	userAgent = "task-todo-api-webhook"
+)
+
+var (
+	// ErrRejected is returned when the receiver answers a status which retrying doesn't change
+	ErrRejected = errors.New("webhook rejected")
+	// ErrUndelivered is returned when every attempt fails
+	ErrUndelivered = errors.New("webhook undelivered")
+	// ErrForbiddenAddress is returned along with ErrRejected when the url resolves to a loopback, link-local
+	// or private address, so that webhooks can't reach the internal network
+	ErrForbiddenAddress = errors.New("forbidden address")
+
+	timeNow = time.Now
+)
+
+type Option struct {
+	// Timeout is the timeout of an attempt
+	Timeout time.Duration
+	// MaxRetries is how many times a failed attempt is retried
+	MaxRetries int
+	// Backoff is the wait before the first retry, which doubles every retry
+	Backoff time.Duration
+	// AllowPrivate lets webhooks reach loopback, link-local and private addresses
+	AllowPrivate bool
+}
+
+type OptionFunc func(*Option)
+
+func WithTimeout(timeout time.Duration) OptionFunc {
+	return func(o *Option) {
+		o.Timeout = timeout
+	}
+}
+
+func WithMaxRetries(n int) OptionFunc {
+	return func(o *Option) {
+		o.MaxRetries = n
+	}
+}
+
+func WithBackoff(backoff time.Duration) OptionFunc {
+	return func(o *Option) {
+		o.Backoff = backoff
+	}
+}
+
+func WithAllowPrivate(allow bool) OptionFunc {
+	return func(o *Option) {
+		o.AllowPrivate = allow
+	}
+}
+
+type Sender interface {
+	// Send posts the event to url as JSON signed by the secret of the receiver. Network errors, 408, 429 and 5xx
+	// are retried with exponential backoff, the other statuses but 2xx fail with ErrRejected at once.
+	// Urls which aren't http or https, or resolve to forbidden addresses, fail with ErrRejected too.
+	Send(ctx context.Context, url, secret string, event *models.WebhookEvent) error
+	// MaxDuration is the longest Send takes, which is every attempt timing out plus the backoff between them
+	MaxDuration() time.Duration
+}
+
+type impl struct {
+	client *http.Client
+	opt    Option
+}
+
+func New(opts ...OptionFunc) Sender {
+	opt := Option{
+		Timeout:    10 * time.Second,
+		MaxRetries: 3,
+		Backoff:    time.Second,
+	}
+	for _, f := range opts {
+		f(&opt)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !opt.AllowPrivate {
+		// NOTE: addresses are checked once resolved, so that a host can't resolve to another address after a check.
+		// A proxy would dial the receiver instead, so none is used.
+		dialer.Control = checkAddress
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+	return &impl{
+		client: &http.Client{Timeout: opt.Timeout, Transport: transport},
+		opt:    opt,
+	}
+}
+
+// checkAddress rejects the loopback, link-local and private addresses
+func checkAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// checkURL rejects the urls which aren't http or https
+func checkURL(rawURL string) error {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme %q", ErrRejected, u.Scheme)
+	}
+	return nil
+}
+
+// Sign returns the signature of the body sent at timestamp, which is the hex-encoded HMAC-SHA256 of
+// "<timestamp>.<body>" prefixed with "sha256=". Receivers should compare it in constant time and
+// reject stale timestamps to prevent replays.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (im *impl) Send(ctx context.Context, url, secret string, event *models.WebhookEvent) error {
+	if err := checkURL(url); err != nil {
+		return err
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	backoff := im.opt.Backoff
+	for attempt := 0; ; attempt++ {
+		err = im.post(ctx, url, []byte(secret), event, body)
+		if err == nil || errors.Is(err, ErrRejected) {
+			return err
+		}
+		if attempt >= im.opt.MaxRetries {
+			return fmt.Errorf("%w: %v", ErrUndelivered, err)
+		}
+
+		zerolog.Ctx(ctx).Warn().Err(err).Str("url", url).Int("attempt", attempt+1).Msg("webhook attempt failed")
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", ErrUndelivered, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (im *impl) MaxDuration() time.Duration {
+	d := time.Duration(im.opt.MaxRetries+1) * im.opt.Timeout
+	backoff := im.opt.Backoff
+	for i := 0; i < im.opt.MaxRetries; i++ {
+		d += backoff
+		backoff *= 2
+	}
+	return d
+}
+
+// post makes an attempt, the timestamp and signature are renewed every attempt
+func (im *impl) post(ctx context.Context, url string, secret []byte, event *models.WebhookEvent, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRejected, err)
+	}
+	timestamp := strconv.FormatInt(timeNow().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderID, event.ID.String())
+	req.Header.Set(HeaderEvent, event.Event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	resp, err := im.client.Do(req)
+	if errors.Is(err, ErrForbiddenAddress) {
+		return fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return fmt.Errorf("status %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("%w: status %d", ErrRejected, resp.StatusCode)
+	}
+}
@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+var (
+	mockCTX    = context.Background()
+	mockNow    = time.Date(2024, 1, 10, 9, 30, 0, 0, time.UTC)
+	mockSecret = "mock-secret"
+	mockEvent  = &models.WebhookEvent{
+		ID:        uuid.New(),
+		Event:     "task.reminder",
+		CreatedAt: mockNow,
+		Data:      map[string]string{"name": "mock-task-name"},
+	}
+)
+
+func TestSend(t *testing.T) {
+	timeNow = func() time.Time { return mockNow }
+
+	tests := []struct {
+		desc        string
+		statuses    []int
+		expAttempts int32
+		expErr      error
+	}{
+		{
+			desc:        "delivered at once",
+			statuses:    []int{http.StatusNoContent},
+			expAttempts: 1,
+		},
+		{
+			desc:        "delivered after retries",
+			statuses:    []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusOK},
+			expAttempts: 3,
+		},
+		{
+			desc:        "rejected without retry",
+			statuses:    []int{http.StatusBadRequest},
+			expAttempts: 1,
+			expErr:      ErrRejected,
+		},
+		{
+			desc:        "undelivered after max retries",
+			statuses:    []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			expAttempts: 3,
+			expErr:      ErrUndelivered,
+		},
+	}
+
+	for _, test := range tests {
+		attempts := int32(0)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&attempts, 1)
+
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err, test.desc)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"), test.desc)
+			assert.Equal(t, mockEvent.ID.String(), r.Header.Get(HeaderID), test.desc)
+			assert.Equal(t, mockEvent.Event, r.Header.Get(HeaderEvent), test.desc)
+			timestamp := r.Header.Get(HeaderTimestamp)
+			assert.Equal(t, "1704879000", timestamp, test.desc)
+			assert.Equal(t, Sign([]byte(mockSecret), timestamp, body), r.Header.Get(HeaderSignature), test.desc)
+
+			event := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal(body, &event), test.desc)
+			assert.Equal(t, "task.reminder", event["event"], test.desc)
+
+			w.WriteHeader(test.statuses[n-1])
+		}))
+
+		sender := New(WithMaxRetries(2), WithBackoff(time.Millisecond), WithAllowPrivate(true))
+		err := sender.Send(mockCTX, srv.URL, mockSecret, mockEvent)
+		if test.expErr != nil {
+			assert.ErrorIs(t, err, test.expErr, test.desc)
+		} else {
+			assert.NoError(t, err, test.desc)
+		}
+		assert.Equal(t, test.expAttempts, atomic.LoadInt32(&attempts), test.desc)
+
+		srv.Close()
+	}
+}
+
+func TestSendCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(mockCTX, 50*time.Millisecond)
+	defer cancel()
+	sender := New(WithMaxRetries(10), WithBackoff(time.Hour), WithAllowPrivate(true))
+	start := time.Now()
+	err := sender.Send(ctx, srv.URL, mockSecret, mockEvent)
+	assert.ErrorIs(t, err, ErrUndelivered)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestSendForbidden(t *testing.T) {
+	attempts := int32(0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+	}))
+	defer srv.Close()
+
+	sender := New(WithMaxRetries(2), WithBackoff(time.Millisecond))
+	err := sender.Send(mockCTX, srv.URL, mockSecret, mockEvent)
+	assert.ErrorIs(t, err, ErrRejected)
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+	err = sender.Send(mockCTX, "ftp://example.com", mockSecret, mockEvent)
+	assert.ErrorIs(t, err, ErrRejected)
+	assert.Zero(t, atomic.LoadInt32(&attempts))
+
+	for _, address := range []string{"127.0.0.1:80", "[::1]:80", "10.0.0.1:80", "192.168.1.1:80", "169.254.169.254:80", "0.0.0.0:80"} {
+		assert.ErrorIs(t, checkAddress("tcp", address, nil), ErrForbiddenAddress, address)
+	}
+	assert.NoError(t, checkAddress("tcp", "93.184.216.34:443", nil))
+}
+
+func TestMaxDuration(t *testing.T) {
+	assert.Equal(t, 47*time.Second, New().MaxDuration())
+	assert.Equal(t, 3*time.Second+3*time.Millisecond,
+		New(WithTimeout(time.Second), WithMaxRetries(2), WithBackoff(time.Millisecond)).MaxDuration())
+}
+
+func TestSign(t *testing.T) {
+	// NOTE: echo -n '1704879000.{}' | openssl dgst -sha256 -hmac mock-secret
+	assert.Equal(t, "sha256=47d65705e5084151b19f9b53243f552854acee385b277d3756433e6d402bac4f", Sign([]byte(mockSecret), "1704879000", []byte("{}")))
+}
@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+// Channel is where the pk of every task event written to the outbox is notified, when it's committed
+const Channel = "task_events"
+
+var ErrInvalidCursor = models.BadRequestErr{Code: "INVALID_CURSOR"}
+
+// Cursor is a position in the stream of task events, which are ordered by the transaction writing them and pk.
+// An event is held until every transaction which may write an event before it ends, or until the max hold
+// passes since its transaction started, so that a long or idle transaction of any session can't stall streams.
+// The events of a transaction writing events for longer than the max hold may come before the cursor once
+// they're committed, and be skipped. Setting idle_in_transaction_session_timeout and statement_timeout
+// keeps transactions shorter than the max hold.
+type Cursor struct {
+	XID uint64 `db:"xid"`
+	PK  int64  `db:"pk"`
+}
+
+// CursorOf returns the cursor right after the event
+func CursorOf(e *models.TaskEvent) Cursor {
+	return Cursor{XID: e.XID, PK: e.PK}
+}
+
+// String formats the cursor as "<xid>-<pk>"
+func (c Cursor) String() string {
+	return fmt.Sprintf("%d-%d", c.XID, c.PK)
+}
+
+// ParseCursor parses the cursor formatted by String
+func ParseCursor(s string) (Cursor, error) {
+	xid, pk, ok := strings.Cut(s, "-")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	c := Cursor{}
+	var err error
+	if c.XID, err = strconv.ParseUint(xid, 10, 64); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if c.PK, err = strconv.ParseInt(pk, 10, 64); err != nil || c.PK < 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+type Event interface {
+	// Head returns the cursor streams start from, they get the events committed from now on.
+	// Events committed shortly before may be included.
+	Head(ctx context.Context, maxHold time.Duration) (Cursor, error)
+	// List returns at most limit task events after the cursor, the events held by maxHold and the ones after
+	// them are left out. pending tells whether some events after the cursor are held.
+	List(ctx context.Context, after Cursor, maxHold time.Duration, limit int) (events []*models.TaskEvent, pending bool, err error)
+}
@@ -0,0 +1,74 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/services/postgres"
+)
+
+const (
+	eventColumns = "o.xid, o.pk, o.id, o.event, o.task_id, o.payload, o.created_at"
+	// watermark is the lowest transaction still running, events written by the transactions before it
+	// are all committed or rolled back
+	watermark = "pg_snapshot_xmin(pg_current_snapshot())"
+)
+
+type impl struct {
+	q *postgres.Querier
+}
+
+func New(db *sqlx.DB, opts ...postgres.QueryOptionFunc) Event {
+	return &impl{
+		q: postgres.NewQuerier(db, opts...),
+	}
+}
+
+// heldCond is where the event o is held, which is written after the watermark w and less than the max hold ago.
+// The max hold is given in microseconds by arg.
+func heldCond(arg string) string {
+	return "o.xid >= w.xmin AND o.created_at > now() - " + arg + " * INTERVAL '1 microsecond'"
+}
+
+func (im *impl) Head(ctx context.Context, maxHold time.Duration) (Cursor, error) {
+	// NOTE: the head is after the events released already, so that a long transaction doesn't make new streams
+	// begin with every event since it started
+	s := "WITH w AS (SELECT " + watermark + " AS xmin),\n" +
+		"first_held AS (SELECT o.xid, o.pk FROM outbox o, w WHERE " + heldCond("$1") + " ORDER BY o.xid, o.pk LIMIT 1)\n" +
+		"SELECT COALESCE(r.xid, w.xmin) AS xid, COALESCE(r.pk, 0) AS pk FROM w LEFT JOIN (\n" +
+		"SELECT o.xid, o.pk FROM outbox o, w WHERE o.xid >= w.xmin\n" +
+		"AND NOT EXISTS (SELECT 1 FROM first_held h WHERE (h.xid, h.pk) <= (o.xid, o.pk))\n" +
+		"ORDER BY o.xid DESC, o.pk DESC LIMIT 1) r ON true"
+	c := Cursor{}
+	if err := im.q.Get(ctx, &c, s, maxHold.Microseconds()); err != nil {
+		return Cursor{}, err
+	}
+	return c, nil
+}
+
+// heldEvent is an event along with whether it's held
+type heldEvent struct {
+	models.TaskEvent
+	Held bool `db:"held"`
+}
+
+func (im *impl) List(ctx context.Context, after Cursor, maxHold time.Duration, limit int) ([]*models.TaskEvent, bool, error) {
+	s := "SELECT " + eventColumns + ", " + heldCond("$4") + " AS held FROM outbox o, (SELECT " + watermark + " AS xmin) w\n" +
+		"WHERE (o.xid, o.pk) > ($1::xid8, $2) ORDER BY o.xid, o.pk LIMIT $3"
+	rows := []*heldEvent{}
+	if err := im.q.Select(ctx, &rows, s, after.XID, after.PK, limit, maxHold.Microseconds()); err != nil {
+		return nil, false, err
+	}
+
+	events := make([]*models.TaskEvent, 0, len(rows))
+	for _, r := range rows {
+		if r.Held {
+			return events, true, nil
+		}
+		events = append(events, &r.TaskEvent)
+	}
+	return events, false, nil
+}
@@ -0,0 +1,201 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/services/postgres"
+	"github.com/chihkaiyu/task-todo-api/stores/storetest"
+)
+
+var (
+	mockCTX     = context.Background()
+	mockNow     = time.Now().UTC().Truncate(time.Microsecond)
+	mockUUID    = uuid.New()
+	mockMaxHold = time.Hour
+)
+
+type eventSuite struct {
+	storetest.Suite
+	eventStore *impl
+}
+
+func TestEventSuite(t *testing.T) {
+	suite.Run(t, new(eventSuite))
+}
+
+func (s *eventSuite) SetupTest() {
+	s.Suite.SetupTest()
+	s.eventStore = New(s.DB).(*impl)
+}
+
+func (s *eventSuite) createEvent(q sqlx.Queryer, event string) Cursor {
+	c := Cursor{}
+	err := sqlx.Get(q, &c, "INSERT INTO outbox (id, event, task_id, payload, created_at) VALUES ($1, $2, $3, '{}', $4) RETURNING xid, pk",
+		uuid.New(), event, mockUUID, mockNow)
+	s.Require().NoError(err)
+	return c
+}
+
+func (s *eventSuite) TestList() {
+	c1 := s.createEvent(s.DB, models.TaskEventCreated)
+	c2 := s.createEvent(s.DB, models.TaskEventUpdated)
+	c3 := s.createEvent(s.DB, models.TaskEventDeleted)
+
+	events, pending, err := s.eventStore.List(mockCTX, c1, mockMaxHold, 1)
+	s.Require().NoError(err)
+	s.Require().False(pending)
+	s.Require().Len(events, 1)
+	s.Require().Equal(c2, CursorOf(events[0]))
+	s.Require().Equal(models.TaskEventUpdated, events[0].Event)
+	s.Require().Equal(mockUUID, events[0].TaskID)
+	s.Require().Equal(mockNow, events[0].CreatedAt.UTC())
+
+	events, pending, err = s.eventStore.List(mockCTX, Cursor{}, mockMaxHold, 10)
+	s.Require().NoError(err)
+	s.Require().False(pending)
+	s.Require().Len(events, 3)
+	s.Require().Equal(c3, CursorOf(events[2]))
+
+	events, pending, err = s.eventStore.List(mockCTX, c3, mockMaxHold, 10)
+	s.Require().NoError(err)
+	s.Require().False(pending)
+	s.Require().Empty(events)
+
+	// the head comes after the events committed
+	head, err := s.eventStore.Head(mockCTX, mockMaxHold)
+	s.Require().NoError(err)
+	events, _, err = s.eventStore.List(mockCTX, head, mockMaxHold, 10)
+	s.Require().NoError(err)
+	s.Require().Empty(events)
+}
+
+func (s *eventSuite) TestListCommitOrder() {
+	head, err := s.eventStore.Head(mockCTX, mockMaxHold)
+	s.Require().NoError(err)
+
+	// events are held while a transaction before them is running
+	txA := s.DB.MustBegin()
+	defer txA.Rollback()
+	a := s.createEvent(txA, models.TaskEventCreated)
+	b := s.createEvent(s.DB, models.TaskEventUpdated)
+	events, pending, err := s.eventStore.List(mockCTX, head, mockMaxHold, 10)
+	s.Require().NoError(err)
+	s.Require().True(pending)
+	s.Require().Empty(events)
+
+	s.Require().NoError(txA.Commit())
+	events, pending, err = s.eventStore.List(mockCTX, head, mockMaxHold, 10)
+	s.Require().NoError(err)
+	s.Require().False(pending)
+	s.Require().Len(events, 2)
+	s.Require().Equal(a, CursorOf(events[0]))
+	s.Require().Equal(b, CursorOf(events[1]))
+
+	// an event with a greater pk comes first if its transaction started first, it isn't skipped
+	txC := s.DB.MustBegin()
+	defer txC.Rollback()
+	_, err = txC.Exec("SELECT pg_current_xact_id()")
+	s.Require().NoError(err)
+	d := s.createEvent(s.DB, models.TaskEventUpdated)
+	events, pending, err = s.eventStore.List(mockCTX, b, mockMaxHold, 10)
+	s.Require().NoError(err)
+	s.Require().True(pending)
+	s.Require().Empty(events)
+
+	c := s.createEvent(txC, models.TaskEventCreated)
+	s.Require().NoError(txC.Commit())
+	s.Require().Greater(c.PK, d.PK)
+	events, pending, err = s.eventStore.List(mockCTX, b, mockMaxHold, 10)
+	s.Require().NoError(err)
+	s.Require().False(pending)
+	s.Require().Len(events, 2)
+	s.Require().Equal(c, CursorOf(events[0]))
+	s.Require().Equal(d, CursorOf(events[1]))
+}
+
+func (s *eventSuite) TestListMaxHold() {
+	head, err := s.eventStore.Head(mockCTX, mockMaxHold)
+	s.Require().NoError(err)
+
+	// an idle transaction holds the events after it until the max hold passes
+	tx := s.DB.MustBegin()
+	defer tx.Rollback()
+	_, err = tx.Exec("SELECT pg_current_xact_id()")
+	s.Require().NoError(err)
+	c := s.createEvent(s.DB, models.TaskEventCreated)
+	events, pending, err := s.eventStore.List(mockCTX, head, mockMaxHold, 10)
+	s.Require().NoError(err)
+	s.Require().True(pending)
+	s.Require().Empty(events)
+
+	events, pending, err = s.eventStore.List(mockCTX, head, time.Microsecond, 10)
+	s.Require().NoError(err)
+	s.Require().False(pending)
+	s.Require().Len(events, 1)
+	s.Require().Equal(c, CursorOf(events[0]))
+
+	// new streams start after the events released
+	head, err = s.eventStore.Head(mockCTX, time.Microsecond)
+	s.Require().NoError(err)
+	s.Require().Equal(c, head)
+}
+
+func (s *eventSuite) TestNotify() {
+	ctx, cancel := context.WithCancel(mockCTX)
+	defer cancel()
+	listener := postgres.NewListener(s.URI(), Channel)
+	payloads, unsubscribe := listener.Subscribe()
+	defer unsubscribe()
+	done := make(chan error, 1)
+	go func() {
+		done <- listener.Listen(ctx)
+	}()
+	// NOTE: wait for the listener to listen by probing until a probe is received, probes are skipped afterwards
+	s.Require().Eventually(func() bool {
+		if _, err := s.DB.Exec("SELECT pg_notify($1, '0')", Channel); err != nil {
+			return false
+		}
+		select {
+		case <-payloads:
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 10*time.Second, 10*time.Millisecond)
+	next := func(timeout time.Duration) string {
+		for {
+			select {
+			case payload := <-payloads:
+				if payload != "0" {
+					return payload
+				}
+			case <-time.After(timeout):
+				return ""
+			}
+		}
+	}
+
+	// events are notified once they're committed
+	tx := s.DB.MustBegin()
+	var pk int64
+	s.Require().NoError(tx.Get(&pk, "INSERT INTO outbox (id, event, task_id, payload) VALUES ($1, $2, $3, '{}') RETURNING pk",
+		uuid.New(), models.TaskEventCreated, mockUUID))
+	s.Require().Empty(next(100 * time.Millisecond))
+	s.Require().NoError(tx.Commit())
+	s.Require().Equal(fmt.Sprint(pk), next(5*time.Second))
+
+	// the subscription is closed once the listener stops
+	cancel()
+	s.Require().NoError(<-done)
+	s.Require().Empty(next(time.Second))
+	_, ok := <-payloads
+	s.Require().False(ok)
+}
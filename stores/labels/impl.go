@@ -0,0 +1,110 @@
+package labels
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/services/postgres"
+)
+
+const labelColumns = "pk, id, name, created_at, updated_at"
+
+var timeNow = time.Now
+
+type impl struct {
+	q *postgres.Querier
+}
+
+func New(db *sqlx.DB, opts ...postgres.QueryOptionFunc) Label {
+	return &impl{
+		q: postgres.NewQuerier(db, opts...),
+	}
+}
+
+func (im *impl) Create(ctx context.Context, params *models.CreateLabelParams) (*models.Label, error) {
+	s := "INSERT INTO labels (id, name, created_at, updated_at) VALUES ($1, $2, $3, $3) RETURNING " + labelColumns
+	label := &models.Label{}
+	if err := im.q.Get(ctx, label, s, uuid.New(), params.Name, timeNow().UTC()); err != nil {
+		if postgres.IsDuplicate(err) {
+			return nil, ErrLabelExists
+		}
+		zerolog.Ctx(ctx).Error().Err(err).Msg("im.q.Get failed")
+		return nil, err
+	}
+
+	return label, nil
+}
+
+func (im *impl) Get(ctx context.Context, id string) (*models.Label, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	s := "SELECT " + labelColumns + " FROM labels WHERE id=$1"
+	label := &models.Label{}
+	if err := im.q.Get(ctx, label, s, parsedID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrLabelNotFound
+		}
+		return nil, err
+	}
+
+	return label, nil
+}
+
+func (im *impl) List(ctx context.Context) ([]*models.Label, error) {
+	s := "SELECT " + labelColumns + " FROM labels ORDER BY name, pk"
+	labels := []*models.Label{}
+	if err := im.q.Select(ctx, &labels, s); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+func (im *impl) Put(ctx context.Context, id string, params *models.PutLabelParams) (*models.Label, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	s := "UPDATE labels SET name=$1, updated_at=$2 WHERE id=$3 RETURNING " + labelColumns
+	label := &models.Label{}
+	if err := im.q.Get(ctx, label, s, params.Name, timeNow().UTC(), parsedID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrLabelNotFound
+		}
+		if postgres.IsDuplicate(err) {
+			return nil, ErrLabelExists
+		}
+		return nil, err
+	}
+
+	return label, nil
+}
+
+func (im *impl) Delete(ctx context.Context, id string) error {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	// NOTE: task_labels are removed along by ON DELETE CASCADE
+	res, err := im.q.Exec(ctx, "DELETE FROM labels WHERE id=$1", parsedID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrLabelNotFound
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package labels
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/stores/storetest"
+)
+
+var (
+	mockCTX  = context.Background()
+	mockNow  = time.Now().UTC().Truncate(time.Microsecond)
+	mockUUID = uuid.New()
+)
+
+type labelSuite struct {
+	storetest.Suite
+	labelStore *impl
+}
+
+func TestLabelSuite(t *testing.T) {
+	suite.Run(t, new(labelSuite))
+}
+
+func (s *labelSuite) SetupTest() {
+	s.Suite.SetupTest()
+	s.labelStore = New(s.DB).(*impl)
+
+	// mock functions
+	timeNow = func() time.Time { return mockNow }
+}
+
+func (s *labelSuite) createLabel(id uuid.UUID, name string) {
+	_, err := s.DB.Exec("INSERT INTO labels (id, name, created_at, updated_at) VALUES ($1, $2, $3, $3)", id, name, mockNow)
+	s.Require().NoError(err)
+}
+
+func (s *labelSuite) TestCreate() {
+	tests := []struct {
+		desc     string
+		mockFunc func()
+		name     string
+		expErr   error
+	}{
+		{
+			desc:     "create normally",
+			mockFunc: func() {},
+			name:     "backend",
+		},
+		{
+			desc: "duplicate name",
+			mockFunc: func() {
+				s.createLabel(mockUUID, "backend")
+			},
+			name:   "backend",
+			expErr: ErrLabelExists,
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		test.mockFunc()
+		label, err := s.labelStore.Create(mockCTX, &models.CreateLabelParams{Name: test.name})
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			act, err := s.labelStore.Get(mockCTX, label.ID.String())
+			s.Require().NoError(err, test.desc)
+			s.Require().Equal(label, act, test.desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *labelSuite) TestGet() {
+	tests := []struct {
+		desc   string
+		id     string
+		expErr error
+	}{
+		{
+			desc: "get normally",
+			id:   mockUUID.String(),
+		},
+		{
+			desc:   "not found",
+			id:     uuid.New().String(),
+			expErr: ErrLabelNotFound,
+		},
+		{
+			desc:   "invalid id",
+			id:     "invalid-uuid",
+			expErr: ErrInvalidID,
+		},
+	}
+
+	s.createLabel(mockUUID, "backend")
+	for _, test := range tests {
+		label, err := s.labelStore.Get(mockCTX, test.id)
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			s.Require().Equal("backend", label.Name, test.desc)
+		}
+	}
+}
+
+func (s *labelSuite) TestList() {
+	s.createLabel(uuid.New(), "urgent")
+	s.createLabel(uuid.New(), "backend")
+
+	labels, err := s.labelStore.List(mockCTX)
+	s.Require().NoError(err)
+	s.Require().Len(labels, 2)
+	s.Require().Equal("backend", labels[0].Name)
+	s.Require().Equal("urgent", labels[1].Name)
+}
+
+func (s *labelSuite) TestPut() {
+	tests := []struct {
+		desc     string
+		mockFunc func()
+		id       string
+		name     string
+		expErr   error
+	}{
+		{
+			desc: "rename normally",
+			mockFunc: func() {
+				s.createLabel(mockUUID, "backend")
+			},
+			id:   mockUUID.String(),
+			name: "frontend",
+		},
+		{
+			desc: "name taken",
+			mockFunc: func() {
+				s.createLabel(mockUUID, "backend")
+				s.createLabel(uuid.New(), "frontend")
+			},
+			id:     mockUUID.String(),
+			name:   "frontend",
+			expErr: ErrLabelExists,
+		},
+		{
+			desc:     "not found",
+			mockFunc: func() {},
+			id:       mockUUID.String(),
+			name:     "frontend",
+			expErr:   ErrLabelNotFound,
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		test.mockFunc()
+		label, err := s.labelStore.Put(mockCTX, test.id, &models.PutLabelParams{Name: test.name})
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			s.Require().Equal(test.name, label.Name, test.desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *labelSuite) TestDelete() {
+	tests := []struct {
+		desc     string
+		mockFunc func()
+		id       string
+		expErr   error
+	}{
+		{
+			desc: "delete normally",
+			mockFunc: func() {
+				s.createLabel(mockUUID, "backend")
+			},
+			id: mockUUID.String(),
+		},
+		{
+			desc:     "not found",
+			mockFunc: func() {},
+			id:       mockUUID.String(),
+			expErr:   ErrLabelNotFound,
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		test.mockFunc()
+		err := s.labelStore.Delete(mockCTX, test.id)
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			_, err := s.labelStore.Get(mockCTX, test.id)
+			s.Require().EqualError(err, ErrLabelNotFound.Error(), test.desc)
+		}
+
+		s.TearDownTest()
+	}
+}
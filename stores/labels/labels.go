@@ -0,0 +1,23 @@
+package labels
+
+import (
+	"context"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+var (
+	ErrLabelNotFound = models.NotFoundErr{Code: "LABEL_NOT_FOUND"}
+	ErrInvalidID     = models.BadRequestErr{Code: "INVALID_ID"}
+	ErrLabelExists   = models.ConflictErr{Code: "LABEL_EXISTS"}
+)
+
+type Label interface {
+	Create(ctx context.Context, params *models.CreateLabelParams) (*models.Label, error)
+	Get(ctx context.Context, id string) (*models.Label, error)
+	// List returns every label ordered by name
+	List(ctx context.Context) ([]*models.Label, error)
+	Put(ctx context.Context, id string, params *models.PutLabelParams) (*models.Label, error)
+	// Delete removes the label and detaches it from tasks
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,145 @@
+package lists
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/services/postgres"
+)
+
+const listColumns = "pk, id, name, created_at, updated_at, archived_at"
+
+var timeNow = time.Now
+
+type impl struct {
+	q *postgres.Querier
+}
+
+func New(db *sqlx.DB, opts ...postgres.QueryOptionFunc) List {
+	return &impl{
+		q: postgres.NewQuerier(db, opts...),
+	}
+}
+
+func (im *impl) Create(ctx context.Context, params *models.CreateListParams) (*models.List, error) {
+	s := "INSERT INTO lists (id, name, created_at, updated_at) VALUES ($1, $2, $3, $3) RETURNING " + listColumns
+	list := &models.List{}
+	if err := im.q.Get(ctx, list, s, uuid.New(), params.Name, timeNow().UTC()); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("im.q.Get failed")
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (im *impl) Get(ctx context.Context, id string) (*models.List, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	s := "SELECT " + listColumns + " FROM lists WHERE id=$1"
+	list := &models.List{}
+	if err := im.q.Get(ctx, list, s, parsedID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrListNotFound
+		}
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (im *impl) List(ctx context.Context, withArchived bool) ([]*models.List, error) {
+	s := "SELECT " + listColumns + " FROM lists\n"
+	if !withArchived {
+		s += "WHERE archived_at IS NULL\n"
+	}
+	s += "ORDER BY name, pk"
+
+	lists := []*models.List{}
+	if err := im.q.Select(ctx, &lists, s); err != nil {
+		return nil, err
+	}
+
+	return lists, nil
+}
+
+func (im *impl) Put(ctx context.Context, id string, params *models.PutListParams) (*models.List, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	s := "UPDATE lists SET name=$1, updated_at=$2 WHERE id=$3 RETURNING " + listColumns
+	list := &models.List{}
+	if err := im.q.Get(ctx, list, s, params.Name, timeNow().UTC(), parsedID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrListNotFound
+		}
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (im *impl) Archive(ctx context.Context, id string) (*models.List, error) {
+	now := timeNow().UTC()
+	return im.setArchived(ctx, id, "archived_at IS NULL", now, ErrListArchived)
+}
+
+func (im *impl) Unarchive(ctx context.Context, id string) (*models.List, error) {
+	return im.setArchived(ctx, id, "archived_at IS NOT NULL", nil, ErrListNotArchived)
+}
+
+// setArchived sets archived_at of the list if it meets cond, errMismatch is returned if it doesn't
+func (im *impl) setArchived(ctx context.Context, id, cond string, archivedAt interface{}, errMismatch error) (*models.List, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	s := "UPDATE lists SET archived_at=$1, updated_at=$2 WHERE id=$3 AND " + cond + " RETURNING " + listColumns
+	list := &models.List{}
+	if err := im.q.Get(ctx, list, s, archivedAt, timeNow().UTC(), parsedID); err != nil {
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+		if _, err := im.Get(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, errMismatch
+	}
+
+	return list, nil
+}
+
+func (im *impl) Delete(ctx context.Context, id string) error {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	// NOTE: deleted tasks left in the list are moved out of it by ON DELETE SET NULL
+	s := "DELETE FROM lists WHERE id=$1 AND NOT EXISTS (\n" +
+		"SELECT 1 FROM tasks WHERE list_id=$1 AND deleted_at IS NULL)"
+	res, err := im.q.Exec(ctx, s, parsedID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		if _, err := im.Get(ctx, id); err != nil {
+			return err
+		}
+		return ErrListNotEmpty
+	}
+	return nil
+}
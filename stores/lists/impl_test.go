@@ -0,0 +1,286 @@
+package lists
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/stores/storetest"
+)
+
+var (
+	mockCTX  = context.Background()
+	mockNow  = time.Now().UTC().Truncate(time.Microsecond)
+	mockUUID = uuid.New()
+)
+
+type listSuite struct {
+	storetest.Suite
+	listStore *impl
+}
+
+func TestListSuite(t *testing.T) {
+	suite.Run(t, new(listSuite))
+}
+
+func (s *listSuite) SetupTest() {
+	s.Suite.SetupTest()
+	s.listStore = New(s.DB).(*impl)
+
+	// mock functions
+	timeNow = func() time.Time { return mockNow }
+}
+
+func (s *listSuite) createList(id uuid.UUID, name string, archived bool) {
+	archivedAt := sql.NullTime{Time: mockNow, Valid: archived}
+	_, err := s.DB.Exec("INSERT INTO lists (id, name, created_at, updated_at, archived_at) VALUES ($1, $2, $3, $3, $4)",
+		id, name, mockNow, archivedAt)
+	s.Require().NoError(err)
+}
+
+func (s *listSuite) createTask(listID uuid.UUID, deleted bool) uuid.UUID {
+	id := uuid.New()
+	deletedAt := sql.NullTime{Time: mockNow, Valid: deleted}
+	_, err := s.DB.Exec("INSERT INTO tasks (id, list_id, name, created_at, updated_at, deleted_at, position) VALUES ($1, $2, 'task', $3, $3, $4, 'i' || (SELECT COUNT(*) FROM tasks))",
+		id, listID, mockNow, deletedAt)
+	s.Require().NoError(err)
+	return id
+}
+
+func (s *listSuite) TestCreate() {
+	list, err := s.listStore.Create(mockCTX, &models.CreateListParams{Name: "work"})
+	s.Require().NoError(err)
+	s.Require().Equal("work", list.Name)
+	s.Require().Equal(mockNow, list.CreatedAt.UTC())
+	s.Require().False(list.ArchivedAt.Valid)
+
+	act, err := s.listStore.Get(mockCTX, list.ID.String())
+	s.Require().NoError(err)
+	s.Require().Equal(list, act)
+}
+
+func (s *listSuite) TestGet() {
+	tests := []struct {
+		desc   string
+		id     string
+		expErr error
+	}{
+		{
+			desc: "get normally",
+			id:   mockUUID.String(),
+		},
+		{
+			desc:   "not found",
+			id:     uuid.New().String(),
+			expErr: ErrListNotFound,
+		},
+		{
+			desc:   "invalid id",
+			id:     "invalid-uuid",
+			expErr: ErrInvalidID,
+		},
+	}
+
+	s.createList(mockUUID, "work", false)
+	for _, test := range tests {
+		list, err := s.listStore.Get(mockCTX, test.id)
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			s.Require().Equal("work", list.Name, test.desc)
+		}
+	}
+}
+
+func (s *listSuite) TestList() {
+	s.createList(uuid.New(), "work", false)
+	s.createList(uuid.New(), "home", false)
+	s.createList(uuid.New(), "archive", true)
+
+	lists, err := s.listStore.List(mockCTX, false)
+	s.Require().NoError(err)
+	s.Require().Len(lists, 2)
+	s.Require().Equal("home", lists[0].Name)
+	s.Require().Equal("work", lists[1].Name)
+
+	lists, err = s.listStore.List(mockCTX, true)
+	s.Require().NoError(err)
+	s.Require().Len(lists, 3)
+	s.Require().Equal("archive", lists[0].Name)
+}
+
+func (s *listSuite) TestPut() {
+	tests := []struct {
+		desc     string
+		mockFunc func()
+		id       string
+		expErr   error
+	}{
+		{
+			desc: "rename normally",
+			mockFunc: func() {
+				s.createList(mockUUID, "work", false)
+			},
+			id: mockUUID.String(),
+		},
+		{
+			desc: "rename archived list",
+			mockFunc: func() {
+				s.createList(mockUUID, "work", true)
+			},
+			id: mockUUID.String(),
+		},
+		{
+			desc:     "not found",
+			mockFunc: func() {},
+			id:       mockUUID.String(),
+			expErr:   ErrListNotFound,
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		test.mockFunc()
+		list, err := s.listStore.Put(mockCTX, test.id, &models.PutListParams{Name: "office"})
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			s.Require().Equal("office", list.Name, test.desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *listSuite) TestArchive() {
+	tests := []struct {
+		desc     string
+		mockFunc func()
+		archive  bool
+		expErr   error
+	}{
+		{
+			desc: "archive normally",
+			mockFunc: func() {
+				s.createList(mockUUID, "work", false)
+			},
+			archive: true,
+		},
+		{
+			desc: "archive archived list",
+			mockFunc: func() {
+				s.createList(mockUUID, "work", true)
+			},
+			archive: true,
+			expErr:  ErrListArchived,
+		},
+		{
+			desc: "unarchive normally",
+			mockFunc: func() {
+				s.createList(mockUUID, "work", true)
+			},
+			archive: false,
+		},
+		{
+			desc: "unarchive list which isn't archived",
+			mockFunc: func() {
+				s.createList(mockUUID, "work", false)
+			},
+			archive: false,
+			expErr:  ErrListNotArchived,
+		},
+		{
+			desc:     "not found",
+			mockFunc: func() {},
+			archive:  true,
+			expErr:   ErrListNotFound,
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		test.mockFunc()
+		var list *models.List
+		var err error
+		if test.archive {
+			list, err = s.listStore.Archive(mockCTX, mockUUID.String())
+		} else {
+			list, err = s.listStore.Unarchive(mockCTX, mockUUID.String())
+		}
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			s.Require().Equal(test.archive, list.ArchivedAt.Valid, test.desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *listSuite) TestDelete() {
+	tests := []struct {
+		desc     string
+		mockFunc func()
+		expErr   error
+	}{
+		{
+			desc: "delete normally",
+			mockFunc: func() {
+				s.createList(mockUUID, "work", false)
+			},
+		},
+		{
+			desc: "delete list with deleted tasks",
+			mockFunc: func() {
+				s.createList(mockUUID, "work", false)
+				s.createTask(mockUUID, true)
+			},
+		},
+		{
+			desc: "delete list with tasks",
+			mockFunc: func() {
+				s.createList(mockUUID, "work", false)
+				s.createTask(mockUUID, false)
+			},
+			expErr: ErrListNotEmpty,
+		},
+		{
+			desc:     "not found",
+			mockFunc: func() {},
+			expErr:   ErrListNotFound,
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		test.mockFunc()
+		err := s.listStore.Delete(mockCTX, mockUUID.String())
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			_, err := s.listStore.Get(mockCTX, mockUUID.String())
+			s.Require().EqualError(err, ErrListNotFound.Error(), test.desc)
+
+			count := 0
+			s.Require().NoError(s.DB.Get(&count, "SELECT COUNT(*) FROM tasks WHERE list_id IS NOT NULL"), test.desc)
+			s.Require().Zero(count, test.desc)
+		}
+
+		s.TearDownTest()
+	}
+}
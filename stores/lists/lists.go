@@ -0,0 +1,28 @@
+package lists
+
+import (
+	"context"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+var (
+	ErrListNotFound    = models.NotFoundErr{Code: "LIST_NOT_FOUND"}
+	ErrInvalidID       = models.BadRequestErr{Code: "INVALID_ID"}
+	ErrListNotEmpty    = models.ConflictErr{Code: "LIST_NOT_EMPTY"}
+	ErrListArchived    = models.ConflictErr{Code: "LIST_ARCHIVED"}
+	ErrListNotArchived = models.ConflictErr{Code: "LIST_NOT_ARCHIVED"}
+)
+
+type List interface {
+	Create(ctx context.Context, params *models.CreateListParams) (*models.List, error)
+	Get(ctx context.Context, id string) (*models.List, error)
+	// List returns the lists ordered by name, archived ones are left out unless withArchived is true
+	List(ctx context.Context, withArchived bool) ([]*models.List, error)
+	// Put renames the list
+	Put(ctx context.Context, id string, params *models.PutListParams) (*models.List, error)
+	Archive(ctx context.Context, id string) (*models.List, error)
+	Unarchive(ctx context.Context, id string) (*models.List, error)
+	// Delete removes the list, which must have no task but deleted ones. Deleted tasks are moved out of it.
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,149 @@
+package reminders
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/services/postgres"
+)
+
+const (
+	// fireAt is when the reminder r of the task t fires
+	fireAt          = "COALESCE(r.remind_at, t.due_at + r.offset_minutes * INTERVAL '1 minute')"
+	reminderColumns = "r.pk, r.id, r.task_id, r.remind_at, r.offset_minutes, " + fireAt + " AS fire_at, " +
+		"r.attempts, r.next_attempt_at, r.sent_at, r.failed_at, r.last_error, r.created_at"
+	reminderFrom = " FROM reminders r JOIN tasks t ON t.id=r.task_id"
+)
+
+var (
+	timeNow = time.Now
+
+	// claims finishes the claimed reminders
+	claims = postgres.Lease{Table: "reminders", DoneColumn: "sent_at", FailedColumn: "failed_at", ErrLost: ErrClaimLost}
+)
+
+type impl struct {
+	q *postgres.Querier
+}
+
+func New(db *sqlx.DB, opts ...postgres.QueryOptionFunc) Reminder {
+	return &impl{
+		q: postgres.NewQuerier(db, opts...),
+	}
+}
+
+func (im *impl) Create(ctx context.Context, taskID string, params *models.CreateReminderParams) (*models.Reminder, error) {
+	parsedTaskID, err := uuid.Parse(taskID)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	dueAt := pq.NullTime{}
+	if err := im.q.Get(ctx, &dueAt, "SELECT due_at FROM tasks WHERE id=$1 AND deleted_at IS NULL", parsedTaskID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	if params.OffsetMinutes != nil && !dueAt.Valid {
+		return nil, ErrReminderWithoutDueAt
+	}
+
+	remindAt := pq.NullTime{}
+	if params.RemindAt != nil {
+		remindAt = pq.NullTime{Time: params.RemindAt.UTC(), Valid: true}
+	}
+	offset := sql.NullInt32{}
+	if params.OffsetMinutes != nil {
+		offset = sql.NullInt32{Int32: int32(*params.OffsetMinutes), Valid: true}
+	}
+
+	id := uuid.New()
+	s := "INSERT INTO reminders (id, task_id, remind_at, offset_minutes, created_at) VALUES ($1, $2, $3, $4, $5)"
+	if _, err := im.q.Exec(ctx, s, id, parsedTaskID, remindAt, offset, timeNow().UTC()); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("im.q.Exec failed")
+		return nil, err
+	}
+
+	reminder := &models.Reminder{}
+	if err := im.q.Get(ctx, reminder, "SELECT "+reminderColumns+reminderFrom+" WHERE r.id=$1", id); err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+func (im *impl) List(ctx context.Context, taskID string) ([]*models.Reminder, error) {
+	parsedTaskID, err := uuid.Parse(taskID)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	exists := false
+	if err := im.q.Get(ctx, &exists, "SELECT EXISTS (SELECT 1 FROM tasks WHERE id=$1 AND deleted_at IS NULL)", parsedTaskID); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrTaskNotFound
+	}
+
+	s := "SELECT " + reminderColumns + reminderFrom + " WHERE r.task_id=$1 ORDER BY fire_at, r.pk"
+	reminders := []*models.Reminder{}
+	if err := im.q.Select(ctx, &reminders, s, parsedTaskID); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (im *impl) Delete(ctx context.Context, taskID, id string) error {
+	parsedTaskID, err := uuid.Parse(taskID)
+	if err != nil {
+		return ErrInvalidID
+	}
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	res, err := im.q.Exec(ctx, "DELETE FROM reminders WHERE id=$1 AND task_id=$2", parsedID, parsedTaskID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrReminderNotFound
+	}
+	return nil
+}
+
+func (im *impl) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.DueReminder, error) {
+	// NOTE: the claim is committed at once instead of holding the rows locked while the reminders are sent
+	s := "WITH due AS (\n" +
+		"SELECT r.pk FROM reminders r JOIN tasks t ON t.id=r.task_id\n" +
+		"WHERE r.sent_at IS NULL AND r.failed_at IS NULL AND (r.next_attempt_at IS NULL OR r.next_attempt_at <= $1)\n" +
+		"AND t.deleted_at IS NULL AND t.status NOT IN ('done', 'archived') AND " + fireAt + " <= $1\n" +
+		"ORDER BY r.pk LIMIT $3 FOR UPDATE OF r SKIP LOCKED)\n" +
+		"UPDATE reminders r SET attempts=r.attempts+1, next_attempt_at=$2 FROM due, tasks t\n" +
+		"WHERE r.pk=due.pk AND t.id=r.task_id\n" +
+		"RETURNING " + reminderColumns + ", t.name AS task_name, t.due_at AS task_due_at"
+	reminders := []*models.DueReminder{}
+	if err := im.q.Select(ctx, &reminders, s, now, now.Add(lease), limit); err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (im *impl) MarkSent(ctx context.Context, id uuid.UUID, attempts int, now time.Time) error {
+	return claims.MarkDone(ctx, im.q, id, attempts, now)
+}
+
+func (im *impl) MarkFailed(ctx context.Context, id uuid.UUID, attempts int, reason string, now, retryAt time.Time) error {
+	return claims.MarkFailed(ctx, im.q, id, attempts, reason, now, retryAt)
+}
@@ -0,0 +1,206 @@
+package reminders
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/stores/storetest"
+)
+
+var (
+	mockCTX  = context.Background()
+	mockNow  = time.Now().UTC().Truncate(time.Microsecond)
+	mockUUID = uuid.New()
+)
+
+type reminderSuite struct {
+	storetest.Suite
+	reminderStore *impl
+}
+
+func TestReminderSuite(t *testing.T) {
+	suite.Run(t, new(reminderSuite))
+}
+
+func (s *reminderSuite) SetupTest() {
+	s.Suite.SetupTest()
+	s.reminderStore = New(s.DB).(*impl)
+
+	// mock functions
+	timeNow = func() time.Time { return mockNow }
+}
+
+func (s *reminderSuite) createTask(id uuid.UUID, dueAt *time.Time, status models.TaskStatus, deleted bool) {
+	deletedAt := sql.NullTime{Time: mockNow, Valid: deleted}
+	_, err := s.DB.Exec("INSERT INTO tasks (id, name, status, due_at, created_at, updated_at, deleted_at, position) "+
+		"VALUES ($1, 'task', $2, $3, $4, $4, $5, 'i' || (SELECT COUNT(*) FROM tasks))", id, status, dueAt, mockNow, deletedAt)
+	s.Require().NoError(err)
+}
+
+func (s *reminderSuite) createReminder(taskID uuid.UUID, remindAt *time.Time, offset *int) uuid.UUID {
+	id := uuid.New()
+	_, err := s.DB.Exec("INSERT INTO reminders (id, task_id, remind_at, offset_minutes) VALUES ($1, $2, $3, $4)",
+		id, taskID, remindAt, offset)
+	s.Require().NoError(err)
+	return id
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func (s *reminderSuite) TestCreate() {
+	dueAt := mockNow.Add(time.Hour)
+
+	tests := []struct {
+		desc      string
+		mockFunc  func()
+		params    *models.CreateReminderParams
+		expFireAt time.Time
+		expErr    error
+	}{
+		{
+			desc: "create at absolute time",
+			mockFunc: func() {
+				s.createTask(mockUUID, nil, models.TaskStatusTodo, false)
+			},
+			params:    &models.CreateReminderParams{RemindAt: timePtr(mockNow.Add(time.Minute))},
+			expFireAt: mockNow.Add(time.Minute),
+		},
+		{
+			desc: "create relative to due date",
+			mockFunc: func() {
+				s.createTask(mockUUID, &dueAt, models.TaskStatusTodo, false)
+			},
+			params:    &models.CreateReminderParams{OffsetMinutes: intPtr(-15)},
+			expFireAt: dueAt.Add(-15 * time.Minute),
+		},
+		{
+			desc: "create relative to missing due date",
+			mockFunc: func() {
+				s.createTask(mockUUID, nil, models.TaskStatusTodo, false)
+			},
+			params: &models.CreateReminderParams{OffsetMinutes: intPtr(-15)},
+			expErr: ErrReminderWithoutDueAt,
+		},
+		{
+			desc: "create for deleted task",
+			mockFunc: func() {
+				s.createTask(mockUUID, nil, models.TaskStatusTodo, true)
+			},
+			params: &models.CreateReminderParams{RemindAt: timePtr(mockNow)},
+			expErr: ErrTaskNotFound,
+		},
+		{
+			desc:     "create for missing task",
+			mockFunc: func() {},
+			params:   &models.CreateReminderParams{RemindAt: timePtr(mockNow)},
+			expErr:   ErrTaskNotFound,
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		test.mockFunc()
+		reminder, err := s.reminderStore.Create(mockCTX, mockUUID.String(), test.params)
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			s.Require().Equal(mockUUID, reminder.TaskID, test.desc)
+			s.Require().True(test.expFireAt.Equal(reminder.FireAt.Time), test.desc)
+			s.Require().Equal(models.ReminderStatusPending, reminder.Status(), test.desc)
+			s.Require().Equal(mockNow, reminder.CreatedAt.UTC(), test.desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *reminderSuite) TestList() {
+	dueAt := mockNow.Add(time.Hour)
+	s.createTask(mockUUID, &dueAt, models.TaskStatusTodo, false)
+	later := s.createReminder(mockUUID, timePtr(mockNow.Add(2*time.Hour)), nil)
+	earlier := s.createReminder(mockUUID, nil, intPtr(-30))
+
+	reminders, err := s.reminderStore.List(mockCTX, mockUUID.String())
+	s.Require().NoError(err)
+	s.Require().Len(reminders, 2)
+	s.Require().Equal(earlier, reminders[0].ID)
+	s.Require().Equal(later, reminders[1].ID)
+
+	_, err = s.reminderStore.List(mockCTX, uuid.New().String())
+	s.Require().EqualError(err, ErrTaskNotFound.Error())
+}
+
+func (s *reminderSuite) TestDelete() {
+	s.createTask(mockUUID, nil, models.TaskStatusTodo, false)
+	id := s.createReminder(mockUUID, timePtr(mockNow), nil)
+
+	s.Require().EqualError(s.reminderStore.Delete(mockCTX, uuid.New().String(), id.String()), ErrReminderNotFound.Error())
+	s.Require().NoError(s.reminderStore.Delete(mockCTX, mockUUID.String(), id.String()))
+	s.Require().EqualError(s.reminderStore.Delete(mockCTX, mockUUID.String(), id.String()), ErrReminderNotFound.Error())
+}
+
+func (s *reminderSuite) TestClaim() {
+	dueAt := mockNow.Add(10 * time.Minute)
+	done := uuid.New()
+	deleted := uuid.New()
+	s.createTask(mockUUID, &dueAt, models.TaskStatusTodo, false)
+	s.createTask(done, nil, models.TaskStatusDone, false)
+	s.createTask(deleted, nil, models.TaskStatusTodo, true)
+	absolute := s.createReminder(mockUUID, timePtr(mockNow.Add(-time.Minute)), nil)
+	relative := s.createReminder(mockUUID, nil, intPtr(-15))
+	s.createReminder(mockUUID, timePtr(mockNow.Add(time.Minute)), nil)
+	s.createReminder(mockUUID, nil, intPtr(-5))
+	s.createReminder(done, timePtr(mockNow.Add(-time.Minute)), nil)
+	s.createReminder(deleted, timePtr(mockNow.Add(-time.Minute)), nil)
+
+	due, err := s.reminderStore.Claim(mockCTX, mockNow, time.Minute, 10)
+	s.Require().NoError(err)
+	s.Require().Len(due, 2)
+	s.Require().Equal(absolute, due[0].ID)
+	s.Require().Equal(relative, due[1].ID)
+	s.Require().Equal(1, due[0].Attempts)
+	s.Require().Equal("task", due[0].TaskName)
+	s.Require().True(dueAt.Equal(due[0].TaskDueAt.Time))
+
+	// claimed reminders are left out until the lease passes
+	due, err = s.reminderStore.Claim(mockCTX, mockNow, time.Minute, 10)
+	s.Require().NoError(err)
+	s.Require().Empty(due)
+
+	s.Require().NoError(s.reminderStore.MarkSent(mockCTX, absolute, 1, mockNow))
+	s.Require().EqualError(s.reminderStore.MarkSent(mockCTX, absolute, 1, mockNow), ErrClaimLost.Error())
+	s.Require().NoError(s.reminderStore.MarkFailed(mockCTX, relative, 1, "mock-error", mockNow, mockNow.Add(time.Second)))
+	due, err = s.reminderStore.Claim(mockCTX, mockNow.Add(time.Second), time.Minute, 10)
+	s.Require().NoError(err)
+	s.Require().Len(due, 1)
+	s.Require().Equal(relative, due[0].ID)
+	s.Require().Equal(2, due[0].Attempts)
+	s.Require().Equal("mock-error", due[0].LastError.String)
+
+	// the claim which has been claimed again can't be finished
+	s.Require().EqualError(s.reminderStore.MarkFailed(mockCTX, relative, 1, "mock-error", mockNow, time.Time{}), ErrClaimLost.Error())
+	s.Require().NoError(s.reminderStore.MarkFailed(mockCTX, relative, 2, "mock-error", mockNow, time.Time{}))
+	reminders, err := s.reminderStore.List(mockCTX, mockUUID.String())
+	s.Require().NoError(err)
+	statuses := map[uuid.UUID]models.ReminderStatus{}
+	for _, r := range reminders {
+		statuses[r.ID] = r.Status()
+	}
+	s.Require().Equal(models.ReminderStatusSent, statuses[absolute])
+	s.Require().Equal(models.ReminderStatusFailed, statuses[relative])
+}
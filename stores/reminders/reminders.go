@@ -0,0 +1,38 @@
+package reminders
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+var (
+	ErrReminderNotFound     = models.NotFoundErr{Code: "REMINDER_NOT_FOUND"}
+	ErrTaskNotFound         = models.NotFoundErr{Code: "TASK_NOT_FOUND"}
+	ErrInvalidID            = models.BadRequestErr{Code: "INVALID_ID"}
+	ErrReminderWithoutDueAt = models.BadRequestErr{Code: "REMINDER_WITHOUT_DUE_AT"}
+	// ErrClaimLost is returned when the reminder is claimed again after the lease passes, or finished by another caller
+	ErrClaimLost = models.ConflictErr{Code: "CLAIM_LOST"}
+)
+
+type Reminder interface {
+	// Create adds a reminder to the task, which mustn't be deleted
+	Create(ctx context.Context, taskID string, params *models.CreateReminderParams) (*models.Reminder, error)
+	// List returns the reminders of the task ordered by when they fire
+	List(ctx context.Context, taskID string) ([]*models.Reminder, error)
+	Delete(ctx context.Context, taskID, id string) error
+
+	// Claim takes at most limit pending reminders which fire by now, their tasks are neither done, archived
+	// nor deleted. Claimed reminders can't be claimed again until lease passes, so that reminders claimed by
+	// a dispatcher which goes away are retried. Reminders claimed by another caller at the same time are skipped.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.DueReminder, error)
+	// MarkSent marks the claimed reminder sent. attempts is the one of the claim, ErrClaimLost is returned
+	// if the reminder has been claimed again or finished since.
+	MarkSent(ctx context.Context, id uuid.UUID, attempts int, now time.Time) error
+	// MarkFailed records the failure of sending the claimed reminder, which is retried after retryAt
+	// or given up if retryAt is zero. The claim is checked like MarkSent.
+	MarkFailed(ctx context.Context, id uuid.UUID, attempts int, reason string, now, retryAt time.Time) error
+}
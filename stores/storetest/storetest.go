@@ -0,0 +1,82 @@
+// Package storetest runs the tests of stores against PostgreSQL in docker.
+package storetest
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	migrate "github.com/rubenv/sql-migrate"
+	"github.com/stretchr/testify/suite"
+
+	bdocker "github.com/chihkaiyu/task-todo-api/base/docker"
+	"github.com/chihkaiyu/task-todo-api/services/postgres"
+)
+
+const (
+	dbName = "gogolook"
+	// NOTE: tests run in the directory of their package, which is stores/<name>
+	migrationDir = "../../infra/databases/api/migrations"
+)
+
+// Suite creates a database migrated to the latest schema before each test and clears it after.
+// Suites of stores embed it and build their stores on DB in their own SetupTest.
+type Suite struct {
+	suite.Suite
+	DB           *sqlx.DB
+	postgresPort string
+}
+
+func (s *Suite) SetupSuite() {
+	ports, err := bdocker.RunExternal([]string{"postgres"})
+	s.Require().NoError(err)
+	s.postgresPort = ports[0]
+}
+
+func (s *Suite) TearDownSuite() {
+	s.NoError(bdocker.RemoveExternal())
+}
+
+func (s *Suite) SetupTest() {
+	s.Require().NoError(createDB(dbName, s.postgresPort))
+	s.Require().NoError(migrateDB(dbName, s.postgresPort))
+
+	db, err := postgres.New(s.URI())
+	s.Require().NoError(err)
+	s.DB = db
+}
+
+func (s *Suite) TearDownTest() {
+	s.DB.Close()
+	s.Require().NoError(bdocker.ClearPostgres(s.postgresPort))
+}
+
+// URI is the connection string of the test database
+func (s *Suite) URI() string {
+	return fmt.Sprintf("postgres://postgres@localhost:%s/%s?sslmode=disable", s.postgresPort, dbName)
+}
+
+func createDB(name, port string) error {
+	db, err := sql.Open("postgres", fmt.Sprintf("postgres://postgres@localhost:%s/?sslmode=disable", port))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec("CREATE DATABASE " + name)
+	return err
+}
+
+func migrateDB(name, port string) error {
+	db, err := sql.Open("postgres", fmt.Sprintf("postgres://postgres@localhost:%s/%s?sslmode=disable", port, name))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrations := &migrate.FileMigrationSource{
+		Dir: migrationDir,
+	}
+	_, err = migrate.Exec(db, "postgres", migrations, migrate.Up)
+	return err
+}
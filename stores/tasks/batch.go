@@ -0,0 +1,116 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+// errBatchAborted rolls back the transaction of atomic batch once an operation fails
+var errBatchAborted = errors.New("batch aborted")
+
+func (im *impl) Batch(ctx context.Context, ops []*models.BatchTaskOperation, atomic bool) ([]*BatchResult, error) {
+	var results []*BatchResult
+	// NOTE: serializable since operations like moving tasks under others and adding blockers require it
+	err := im.WithTx(ctx, func(t Task) error {
+		txStore := t.(*impl)
+		results = make([]*BatchResult, len(ops))
+		for i, op := range ops {
+			if !atomic {
+				if _, err := txStore.q.Exec(ctx, "SAVEPOINT batch_operation"); err != nil {
+					return err
+				}
+			}
+
+			task, err := txStore.apply(ctx, op)
+			results[i] = &BatchResult{Task: task, Err: err}
+			if err == nil {
+				if !atomic {
+					if _, err := txStore.q.Exec(ctx, "RELEASE SAVEPOINT batch_operation"); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			zerolog.Ctx(ctx).Warn().Err(err).Int("index", i).Str("op", string(op.Op)).Msg("batch operation failed")
+			if retryable(err) {
+				return err
+			}
+			if atomic {
+				for j := range results {
+					switch {
+					case j < i:
+						results[j] = &BatchResult{Err: ErrBatchRolledBack}
+					case j > i:
+						results[j] = &BatchResult{Err: ErrBatchSkipped}
+					}
+				}
+				return errBatchAborted
+			}
+			if _, err := txStore.q.Exec(ctx, "ROLLBACK TO SAVEPOINT batch_operation"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, WithIsolation(sql.LevelSerializable))
+	if err != nil && err != errBatchAborted {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (im *impl) apply(ctx context.Context, op *models.BatchTaskOperation) (*models.Task, error) {
+	opts := []MutateTaskOptionFunc{}
+	if op.Version != nil {
+		opts = append(opts, WithVersion(*op.Version))
+	}
+
+	switch op.Op {
+	case models.BatchOpCreate:
+		if op.Name == nil {
+			return nil, ErrInvalidBatchOperation
+		}
+		params := &models.CreateTaskParams{
+			Name:     *op.Name,
+			DueAt:    op.DueAt,
+			Labels:   op.Labels,
+			ParentID: op.ParentID,
+			ListID:   op.ListID,
+		}
+		if op.Description != nil {
+			params.Description = *op.Description
+		}
+		if op.Priority != nil {
+			params.Priority = *op.Priority
+		}
+		if op.RRule != nil {
+			params.RRule = *op.RRule
+		}
+		return im.Create(ctx, params)
+	case models.BatchOpUpdate:
+		return im.Patch(ctx, op.ID, &models.PatchTaskParams{
+			Name:          op.Name,
+			Description:   op.Description,
+			Status:        op.Status,
+			Priority:      op.Priority,
+			DueAt:         op.DueAt,
+			ClearDueAt:    op.ClearDueAt,
+			Labels:        op.Labels,
+			ParentID:      op.ParentID,
+			ClearParentID: op.ClearParentID,
+			ListID:        op.ListID,
+			ClearListID:   op.ClearListID,
+			RRule:         op.RRule,
+			ClearRRule:    op.ClearRRule,
+		}, opts...)
+	case models.BatchOpDelete:
+		return nil, im.Delete(ctx, op.ID, opts...)
+	default:
+		return nil, ErrInvalidBatchOperation
+	}
+}
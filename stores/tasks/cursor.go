@@ -0,0 +1,99 @@
+package tasks
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	defaultSort = "position"
+	rankSort    = "rank"
+
+	dependencySort  = "dependency"
+	dependencyDepth = "(WITH RECURSIVE chain AS (SELECT tasks.pk AS pk, 0 AS depth\n" +
+		"UNION ALL SELECT d.blocker_pk, c.depth+1 FROM task_dependencies d JOIN chain c ON d.task_pk=c.pk)\n" +
+		"SELECT MAX(depth) FROM chain)"
+)
+
+type sortColumn struct {
+	expr string
+	// typ is the SQL type the cursor value is casted back to
+	typ string
+}
+
+var sortColumns = map[string]sortColumn{
+	"created_at": {expr: "created_at", typ: "TIMESTAMP WITH TIME ZONE"},
+	"updated_at": {expr: "updated_at", typ: "TIMESTAMP WITH TIME ZONE"},
+	"name":       {expr: "name", typ: "TEXT"},
+	// NOTE: tasks without due date are placed after the others in ascending order
+	"due_at":   {expr: "COALESCE(due_at, 'infinity')", typ: "TIMESTAMP WITH TIME ZONE"},
+	"priority": {expr: "priority", typ: "SMALLINT"},
+	"position": {expr: "position", typ: "TEXT"},
+	// NOTE: the depth is the length of the longest chain of blockers above the task, so that every task
+	// comes after its blockers in ascending order
+	dependencySort: {expr: dependencyDepth, typ: "INTEGER"},
+	// NOTE: the placeholder is filled with the query of WithSearch
+	rankSort: {expr: "ts_rank(search, to_tsquery('simple', %s))", typ: "REAL"},
+}
+
+type sortOrder struct {
+	key  string
+	desc bool
+	col  sortColumn
+}
+
+func parseSort(sort string) (*sortOrder, error) {
+	if sort == "" {
+		sort = defaultSort
+	}
+	so := &sortOrder{key: sort}
+	if strings.HasPrefix(sort, "-") {
+		so.key = sort[1:]
+		so.desc = true
+	}
+	col, ok := sortColumns[so.key]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+	so.col = col
+	return so, nil
+}
+
+func (so *sortOrder) direction() string {
+	if so.desc {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+func (so *sortOrder) comparator() string {
+	if so.desc {
+		return "<"
+	}
+	return ">"
+}
+
+// cursor points at the last row of a page, pk breaks ties between rows with the same sort value
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	PK    int    `json:"p"`
+}
+
+func encodeCursor(c *cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	c := &cursor{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return c, nil
+}
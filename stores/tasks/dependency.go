@@ -0,0 +1,114 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+const (
+	// openBlockersQuery selects the blockers of the task which are neither done, archived nor deleted
+	openBlockersQuery = "SELECT 1 FROM task_dependencies d JOIN tasks b ON b.pk=d.blocker_pk " +
+		"WHERE d.task_pk=tasks.pk AND b.deleted_at IS NULL AND b.status NOT IN ('done', 'archived')"
+	openBlockersColumn = "(SELECT COUNT(*) FROM (" + openBlockersQuery + ") ob) AS open_blocker_count"
+
+	// blockersCTE collects the tasks blocking the task $1 directly or transitively, by pk
+	blockersCTE = "WITH RECURSIVE blockers AS (\n" +
+		"SELECT blocker_pk FROM task_dependencies WHERE task_pk=(SELECT pk FROM tasks WHERE id=$1)\n" +
+		"UNION SELECT d.blocker_pk FROM task_dependencies d JOIN blockers b ON d.task_pk=b.blocker_pk)\n"
+)
+
+func (im *impl) AddBlocker(ctx context.Context, id, blockerID string) error {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+	parsedBlockerID, err := uuid.Parse(blockerID)
+	if err != nil {
+		return ErrInvalidID
+	}
+	if parsedID == parsedBlockerID {
+		return ErrDependencyCycle
+	}
+
+	// NOTE: two edges added at once can't see each other and form a cycle unless serialized
+	return im.WithTx(ctx, func(t Task) error {
+		txStore := t.(*impl)
+		task, err := txStore.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if task.DeletedAt.Valid {
+			return ErrTaskDeleted
+		}
+		// NOTE: Get leaves out pk, the edge refers to the tasks by id below
+		blocker, err := txStore.Get(ctx, blockerID)
+		if err == ErrTaskNotFound || (err == nil && blocker.DeletedAt.Valid) {
+			return ErrBlockerNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		// NOTE: the blocker mustn't be blocked by the task already
+		cyclic := false
+		s := blockersCTE + "SELECT EXISTS (SELECT 1 FROM blockers WHERE blocker_pk=(SELECT pk FROM tasks WHERE id=$2))"
+		if err := txStore.q.Get(ctx, &cyclic, s, parsedBlockerID, parsedID); err != nil {
+			return err
+		}
+		if cyclic {
+			return ErrDependencyCycle
+		}
+
+		s = "INSERT INTO task_dependencies (task_pk, blocker_pk)\n" +
+			"SELECT t.pk, b.pk FROM tasks t, tasks b WHERE t.id=$1 AND b.id=$2 ON CONFLICT DO NOTHING"
+		_, err = txStore.q.Exec(ctx, s, parsedID, parsedBlockerID)
+		return err
+	}, WithIsolation(sql.LevelSerializable))
+}
+
+func (im *impl) RemoveBlocker(ctx context.Context, id, blockerID string) error {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+	parsedBlockerID, err := uuid.Parse(blockerID)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	s := "DELETE FROM task_dependencies WHERE task_pk=(SELECT pk FROM tasks WHERE id=$1)\n" +
+		"AND blocker_pk=(SELECT pk FROM tasks WHERE id=$2)"
+	res, err := im.q.Exec(ctx, s, parsedID, parsedBlockerID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		if _, err := im.Get(ctx, id); err != nil {
+			return err
+		}
+		return ErrDependencyNotFound
+	}
+	return nil
+}
+
+func (im *impl) ListBlockers(ctx context.Context, id string) ([]*models.Task, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	s := "SELECT pk, " + taskColumns + " FROM tasks WHERE deleted_at IS NULL AND pk IN (\n" +
+		"SELECT blocker_pk FROM task_dependencies WHERE task_pk=(SELECT pk FROM tasks WHERE id=$1))\n" +
+		"ORDER BY created_at, pk"
+	tasks := []*models.Task{}
+	if err := im.q.Select(ctx, &tasks, s, parsedID); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
@@ -0,0 +1,40 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// descendantsCTE collects the subtasks of the task $1 at every level
+	descendantsCTE = "WITH RECURSIVE descendants AS (\n" +
+		"SELECT id FROM tasks WHERE parent_id=$1\n" +
+		"UNION SELECT t.id FROM tasks t JOIN descendants d ON t.parent_id=d.id)\n"
+	// ancestorsCTE collects the task $1 and its parents up to the top level, UNION stops at a cycle
+	ancestorsCTE = "WITH RECURSIVE ancestors AS (\n" +
+		"SELECT id, parent_id FROM tasks WHERE id=$1\n" +
+		"UNION SELECT t.id, t.parent_id FROM tasks t JOIN ancestors a ON t.id=a.parent_id)\n"
+)
+
+// checkParent verifies that the task can be placed under the parent, which must exist,
+// mustn't be deleted and mustn't be the task itself or one of its subtasks
+func (im *impl) checkParent(ctx context.Context, id, parentID uuid.UUID) error {
+	parent, err := im.Get(ctx, parentID.String())
+	if err == ErrTaskNotFound || (err == nil && parent.DeletedAt.Valid) {
+		return ErrParentNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	cyclic := false
+	s := ancestorsCTE + "SELECT EXISTS (SELECT 1 FROM ancestors WHERE id=$2)"
+	if err := im.q.Get(ctx, &cyclic, s, parentID, id); err != nil {
+		return err
+	}
+	if cyclic {
+		return ErrTaskCycle
+	}
+	return nil
+}
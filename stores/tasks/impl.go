@@ -2,42 +2,131 @@ package tasks
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/services/postgres"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	"github.com/rs/zerolog"
 )
 
-var timeNow = time.Now
+const (
+	// labelsColumn is the names of labels attached to the task, sorted in byte order
+	labelsColumn = "ARRAY(SELECT l.name FROM task_labels tl JOIN labels l ON l.pk=tl.label_pk " +
+		"WHERE tl.task_pk=tasks.pk ORDER BY l.name COLLATE \"C\") AS labels"
+	// progressColumns count the subtasks which are neither deleted nor archived, and the done ones among them
+	progressColumns = "(SELECT COUNT(*) FROM tasks c WHERE c.parent_id=tasks.id AND c.deleted_at IS NULL " +
+		"AND c.status<>'archived') AS subtask_count, " +
+		"(SELECT COUNT(*) FROM tasks c WHERE c.parent_id=tasks.id AND c.deleted_at IS NULL " +
+		"AND c.status='done') AS subtask_done_count"
+	taskColumns = "id, parent_id, list_id, name, description, status, priority, due_at, completed_at, created_at, updated_at, deleted_at, version, position, rrule, series_id, occurrence, " +
+		labelsColumn + ", " + progressColumns + ", " + openBlockersColumn
+
+	purgeBatchSize = 1000
+)
+
+var (
+	timeNow = time.Now
+
+	likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+)
+
+// sqlArgs collects positional arguments while a statement is being built
+type sqlArgs []interface{}
+
+// add appends v and returns its placeholder
+func (a *sqlArgs) add(v interface{}) string {
+	*a = append(*a, v)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// listRow is a task along with the textual value of the column it is sorted by
+type listRow struct {
+	models.Task
+	CursorValue string `db:"cursor_value"`
+}
 
 type impl struct {
 	db *sqlx.DB
+	// q runs the queries on either db or tx
+	q *postgres.Querier
+	// tx is the transaction the store is bound to, nil if it isn't
+	tx *sqlx.Tx
+	// isolation is the isolation level of tx
+	isolation sql.IsolationLevel
 }
 
-func New(db *sqlx.DB) Task {
+func New(db *sqlx.DB, opts ...postgres.QueryOptionFunc) Task {
 	return &impl{
 		db: db,
+		q:  postgres.NewQuerier(db, opts...),
 	}
 }
 
-func (im *impl) Create(ctx context.Context, name string) (*models.Task, error) {
-	s := "INSERT INTO tasks (id, name, status, created_at, updated_at)\n" +
-		"VALUES (:id, :name, :status, :created_at, :updated_at)"
+func (im *impl) Create(ctx context.Context, params *models.CreateTaskParams) (*models.Task, error) {
+	rule, err := nullRRule(params.RRule)
+	if err != nil {
+		return nil, err
+	}
+	if rule.Valid && params.DueAt == nil {
+		return nil, ErrRRuleWithoutDueAt
+	}
+
+	s := "INSERT INTO tasks (id, parent_id, list_id, name, description, status, priority, due_at, created_at, updated_at, version, position, rrule)\n" +
+		"VALUES (:id, :parent_id, :list_id, :name, :description, :status, :priority, :due_at, :created_at, :updated_at, :version, :position, :rrule)"
 	now := timeNow().UTC()
 	task := &models.Task{
-		ID:        uuid.New(),
-		Name:      name,
-		Status:    0,
-		CreatedAt: now,
-		UpdatedAt: now,
-		DeletedAt: pq.NullTime{},
-	}
-	_, err := im.db.NamedExec(s, task)
+		ID:          uuid.New(),
+		ParentID:    models.NullUUID(params.ParentID),
+		ListID:      models.NullUUID(params.ListID),
+		Name:        params.Name,
+		Description: params.Description,
+		Status:      models.TaskStatusTodo,
+		Priority:    params.Priority,
+		DueAt:       models.NullTime(params.DueAt),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		DeletedAt:   pq.NullTime{},
+		Version:     1,
+		RRule:       rule,
+		Occurrence:  1,
+		Labels:      pq.StringArray{},
+	}
+	err = im.WithTx(ctx, func(t Task) error {
+		txStore := t.(*impl)
+		if params.ParentID != nil {
+			if err := txStore.checkParent(ctx, task.ID, *params.ParentID); err != nil {
+				return err
+			}
+		}
+		if params.ListID != nil {
+			if err := txStore.checkList(ctx, *params.ListID); err != nil {
+				return err
+			}
+		}
+		// NOTE: new tasks are placed at the end
+		if task.Position, err = txStore.appendPosition(ctx); err != nil {
+			return err
+		}
+		if _, err := txStore.q.NamedExec(ctx, s, task); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("txStore.q.NamedExec failed")
+			return err
+		}
+		if params.Labels != nil {
+			if task.Labels, err = txStore.setLabels(ctx, task.ID, params.Labels); err != nil {
+				return err
+			}
+		}
+		return txStore.emit(ctx, models.TaskEventCreated, task)
+	})
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("im.db.NamedExec failed")
 		return nil, err
 	}
 
@@ -50,75 +139,544 @@ func (im *impl) Get(ctx context.Context, id string) (*models.Task, error) {
 		return nil, ErrInvalidID
 	}
 
-	s := "SELECT id, name, status, created_at, updated_at, deleted_at FROM tasks WHERE id=$1"
+	s := "SELECT " + taskColumns + " FROM tasks WHERE id=$1"
 	task := &models.Task{}
-	if err := im.db.Get(task, s, parsedID); err != nil {
+	if err := im.q.Get(ctx, task, s, parsedID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTaskNotFound
+		}
 		return nil, err
 	}
 
 	return task, nil
 }
 
-func (im *impl) List(ctx context.Context, opts ...ListTaskOptionFunc) ([]*models.Task, error) {
+func (im *impl) List(ctx context.Context, opts ...ListTaskOptionFunc) ([]*models.Task, string, error) {
 	opt := ListTaskOption{}
 	for _, f := range opts {
 		f(&opt)
 	}
-	s := "SELECT id, name, status, created_at, updated_at, deleted_at FROM tasks\n"
+	if opt.Limit <= 0 {
+		opt.Limit = DefaultListLimit
+	}
+	if opt.Limit > MaxListLimit {
+		opt.Limit = MaxListLimit
+	}
+	sort := opt.Sort
+	query := searchQuery(opt.Search)
+	if sort == "" && query != "" {
+		sort = "-" + rankSort
+	}
+	so, err := parseSort(sort)
+	if err != nil {
+		return nil, "", err
+	}
+	if so.key == rankSort && query == "" {
+		return nil, "", ErrInvalidSort
+	}
+
+	conds := []string{}
+	args := sqlArgs{}
+	expr := so.col.expr
 	if !opt.WithDeleted {
-		s += "WHERE deleted_at IS NULL"
+		conds = append(conds, "deleted_at IS NULL")
+	}
+	if opt.Status != "" {
+		conds = append(conds, "status="+args.add(opt.Status))
+	}
+	if opt.Priority != nil {
+		conds = append(conds, "priority="+args.add(*opt.Priority))
+	}
+	if !opt.DueAfter.IsZero() {
+		conds = append(conds, "due_at >= "+args.add(opt.DueAfter))
+	}
+	if !opt.DueBefore.IsZero() {
+		conds = append(conds, "due_at < "+args.add(opt.DueBefore))
+	}
+	if opt.ParentID != nil {
+		conds = append(conds, "parent_id="+args.add(*opt.ParentID))
+	}
+	if opt.ListID != nil {
+		conds = append(conds, "list_id="+args.add(*opt.ListID))
+	}
+	if len(opt.Labels) > 0 {
+		cond := "pk IN (SELECT tl.task_pk FROM task_labels tl JOIN labels l ON l.pk=tl.label_pk WHERE l.name=ANY(" +
+			args.add(pq.Array(opt.Labels)) + ")"
+		if opt.AllLabels {
+			// NOTE: a task has a label at most once, so it has all of them if it matches as many
+			cond += " GROUP BY tl.task_pk HAVING COUNT(*)=" + args.add(len(uniqueStrings(opt.Labels)))
+		}
+		conds = append(conds, cond+")")
+	}
+	if opt.NameContains != "" {
+		conds = append(conds, "name ILIKE "+args.add("%"+likeEscaper.Replace(opt.NameContains)+"%"))
+	}
+	if query != "" {
+		q := args.add(query)
+		conds = append(conds, fmt.Sprintf("search @@ to_tsquery('simple', %s)", q))
+		if so.key == rankSort {
+			expr = fmt.Sprintf(expr, q)
+		}
+	}
+	if opt.Cursor != "" {
+		c, err := decodeCursor(opt.Cursor)
+		if err != nil || c.Sort != opt.Sort {
+			return nil, "", ErrInvalidCursor
+		}
+		conds = append(conds, fmt.Sprintf("(%s, pk) %s (%s::%s, %s)",
+			expr, so.comparator(), args.add(c.Value), so.col.typ, args.add(c.PK)))
+	}
+
+	s := fmt.Sprintf("SELECT pk, %s, (%s)::TEXT AS cursor_value FROM tasks\n", taskColumns, expr)
+	if len(conds) > 0 {
+		s += "WHERE " + strings.Join(conds, " AND ") + "\n"
+	}
+	s += fmt.Sprintf("ORDER BY %s %s, pk %s LIMIT %s", expr, so.direction(), so.direction(), args.add(opt.Limit+1))
+
+	rows := []*listRow{}
+	if err := im.q.Select(ctx, &rows, s, args...); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(rows) > opt.Limit {
+		rows = rows[:opt.Limit]
+		last := rows[len(rows)-1]
+		next = encodeCursor(&cursor{Sort: opt.Sort, Value: last.CursorValue, PK: last.PK})
 	}
-	tasks := []*models.Task{}
-	if err := im.db.Select(&tasks, s); err != nil {
+	tasks := make([]*models.Task, len(rows))
+	for i, r := range rows {
+		tasks[i] = &r.Task
+	}
+
+	return tasks, next, nil
+}
+
+func uniqueStrings(ss []string) map[string]bool {
+	m := map[string]bool{}
+	for _, s := range ss {
+		m[s] = true
+	}
+	return m
+}
+
+// searchQuery turns the words of a search into a tsquery matching all of them as prefixes,
+// anything else is dropped so that the search can't break the tsquery syntax
+func searchQuery(search string) string {
+	words := strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i := range words {
+		words[i] += ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+func (im *impl) Put(ctx context.Context, id string, params *models.PutTaskParams, opts ...MutateTaskOptionFunc) (*models.Task, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	rule, err := nullRRule(params.RRule)
+	if err != nil {
 		return nil, err
 	}
+	if rule.Valid && params.DueAt == nil {
+		return nil, ErrRRuleWithoutDueAt
+	}
+
+	status := params.Status
+	if status == "" {
+		status = models.TaskStatusTodo
+	}
 
-	return tasks, nil
+	m := &mutation{status: &status, deleted: new(bool)}
+	m.set("name", params.Name)
+	m.set("description", params.Description)
+	m.set("status", status)
+	m.set("priority", params.Priority)
+	m.set("due_at", models.NullTime(params.DueAt))
+	m.set("parent_id", models.NullUUID(params.ParentID))
+	m.parent = params.ParentID
+	m.set("list_id", models.NullUUID(params.ListID))
+	m.list = params.ListID
+	m.set("rrule", rule)
+	labels := params.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	return im.updateTx(ctx, parsedID, m, labels, opts...)
 }
 
-func (im *impl) Put(ctx context.Context, id string, params *models.PutTaskParams) (*models.Task, error) {
+func (im *impl) Patch(ctx context.Context, id string, params *models.PatchTaskParams, opts ...MutateTaskOptionFunc) (*models.Task, error) {
 	parsedID, err := uuid.Parse(id)
 	if err != nil {
 		return nil, ErrInvalidID
 	}
 
-	s := "UPDATE tasks SET name=:name, status=:status, updated_at=:updated_at WHERE id=:id RETURNING id, name, status, created_at, updated_at, deleted_at"
-	now := timeNow().UTC()
-	task := &models.Task{
-		ID:        parsedID,
-		Name:      params.Name,
-		Status:    params.Status,
-		UpdatedAt: now,
+	m := &mutation{status: params.Status, deleted: new(bool)}
+	if params.Name != nil {
+		m.set("name", *params.Name)
+	}
+	if params.Description != nil {
+		m.set("description", *params.Description)
+	}
+	if params.Status != nil {
+		m.set("status", *params.Status)
 	}
-	row, err := im.db.NamedQuery(s, task)
+	if params.Priority != nil {
+		m.set("priority", *params.Priority)
+	}
+	if params.DueAt != nil || params.ClearDueAt {
+		m.set("due_at", models.NullTime(params.DueAt))
+	}
+	if params.ParentID != nil || params.ClearParentID {
+		m.set("parent_id", models.NullUUID(params.ParentID))
+		m.parent = params.ParentID
+	}
+	if params.ListID != nil || params.ClearListID {
+		m.set("list_id", models.NullUUID(params.ListID))
+		m.list = params.ListID
+	}
+	if params.RRule != nil || params.ClearRRule {
+		rule := sql.NullString{}
+		if params.RRule != nil {
+			if rule, err = nullRRule(*params.RRule); err != nil {
+				return nil, err
+			}
+		}
+		m.set("rrule", rule)
+	}
+	if len(m.sets) == 0 && params.Labels == nil {
+		task, err := im.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.check(task, opts...); err != nil {
+			return nil, err
+		}
+		return task, nil
+	}
+	return im.updateTx(ctx, parsedID, m, params.Labels, opts...)
+}
+
+func (im *impl) Restore(ctx context.Context, id string, opts ...MutateTaskOptionFunc) (*models.Task, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	var task *models.Task
+	err = im.WithTx(ctx, func(t Task) error {
+		txStore := t.(*impl)
+		current, err := txStore.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		deleted := true
+		m := &mutation{deleted: &deleted}
+		m.set("deleted_at", nil)
+		if task, err = txStore.update(ctx, parsedID, m, opts...); err != nil {
+			return err
+		}
+		// NOTE: subtasks deleted along with the task are restored as well, the ones deleted on their own aren't
+		restored := []*models.Task{}
+		if err := txStore.q.Select(ctx, &restored, descendantsCTE+"UPDATE tasks SET deleted_at=NULL, version=version+1 "+
+			"WHERE id IN (SELECT id FROM descendants) AND deleted_at=$2 RETURNING "+taskColumns, parsedID, current.DeletedAt); err != nil {
+			return err
+		}
+		return txStore.emit(ctx, models.TaskEventUpdated, restored...)
+	})
 	if err != nil {
 		return nil, err
 	}
+	return task, nil
+}
 
-	updated := &models.Task{}
-	for row.Next() {
-		if err := row.StructScan(updated); err != nil {
+// mutation is an UPDATE on a task along with its preconditions besides MutateTaskOption
+type mutation struct {
+	sets []string
+	args sqlArgs
+	// status is the status the task moves to, nil if it's left untouched
+	status *models.TaskStatus
+	// deleted is the state of deletion the task must be in, nil if either is fine
+	deleted *bool
+	// parent is the task the task is moved under, nil if it's left untouched or becomes top-level
+	parent *uuid.UUID
+	// list is the list the task is moved to, nil if it's left untouched or moves out of any list
+	list *uuid.UUID
+}
+
+func (m *mutation) set(column string, v interface{}) {
+	m.sets = append(m.sets, column+"="+m.args.add(v))
+}
+
+// where returns the conditions of mutating the task
+func (m *mutation) where(id uuid.UUID, opts ...MutateTaskOptionFunc) string {
+	opt := MutateTaskOption{}
+	for _, f := range opts {
+		f(&opt)
+	}
+
+	conds := []string{"id=" + m.args.add(id)}
+	if opt.Version != nil {
+		conds = append(conds, "version="+m.args.add(*opt.Version))
+	}
+	if m.status != nil {
+		conds = append(conds, "status=ANY("+m.args.add(pq.Array(transitSources(*m.status)))+")")
+	}
+	if m.deleted != nil && *m.deleted {
+		conds = append(conds, "deleted_at IS NOT NULL")
+	}
+	if m.deleted != nil && !*m.deleted {
+		conds = append(conds, "deleted_at IS NULL")
+	}
+	if m.status != nil && *m.status == models.TaskStatusDone {
+		conds = append(conds, "(status='done' OR NOT EXISTS ("+openBlockersQuery+"))")
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// check verifies the preconditions against the task in Go, which are the same as where
+func (m *mutation) check(task *models.Task, opts ...MutateTaskOptionFunc) error {
+	opt := MutateTaskOption{}
+	for _, f := range opts {
+		f(&opt)
+	}
+	if opt.Version != nil && *opt.Version != task.Version {
+		return ErrVersionMismatch
+	}
+	if m.status != nil && !canTransit(task.Status, *m.status) {
+		return ErrInvalidStatusTransition
+	}
+	if m.deleted != nil && *m.deleted && !task.DeletedAt.Valid {
+		return ErrTaskNotDeleted
+	}
+	if m.deleted != nil && !*m.deleted && task.DeletedAt.Valid {
+		return ErrTaskDeleted
+	}
+	if m.status != nil && *m.status == models.TaskStatusDone && task.Status != models.TaskStatusDone && task.OpenBlockerCount > 0 {
+		return ErrTaskBlocked
+	}
+	return nil
+}
+
+// update applies the mutation to the task, bumps its version and returns the updated task.
+// It must run in a transaction, which the event of the update is written in.
+func (im *impl) update(ctx context.Context, id uuid.UUID, mut *mutation, opts ...MutateTaskOptionFunc) (*models.Task, error) {
+	// NOTE: work on a copy so that the mutation can be applied again when the transaction is retried
+	m := *mut
+	m.sets = append([]string{}, mut.sets...)
+	m.args = append(sqlArgs{}, mut.args...)
+
+	if m.parent != nil {
+		if err := im.checkParent(ctx, id, *m.parent); err != nil {
+			return nil, err
+		}
+	}
+	if m.list != nil {
+		if err := im.checkList(ctx, *m.list); err != nil {
 			return nil, err
 		}
 	}
-	if updated.ID == uuid.Nil {
-		return nil, ErrTaskNotFound
+
+	now := timeNow().UTC()
+	// NOTE: a task which is already done keeps the time it was completed
+	if m.status != nil && *m.status == models.TaskStatusDone {
+		m.sets = append(m.sets, "completed_at=COALESCE(completed_at, "+m.args.add(now)+")")
+	} else if m.status != nil {
+		m.set("completed_at", nil)
+	}
+	m.set("updated_at", now)
+	m.sets = append(m.sets, "version=version+1")
+	s := fmt.Sprintf("UPDATE tasks SET %s WHERE %s RETURNING %s", strings.Join(m.sets, ", "), m.where(id, opts...), taskColumns)
+
+	updated := &models.Task{}
+	if err := im.q.Get(ctx, updated, s, m.args...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, im.mutateFailure(ctx, id, &m, opts...)
+		}
+		if isRRuleDueAtViolation(err) {
+			return nil, ErrRRuleWithoutDueAt
+		}
+		return nil, err
+	}
+	if err := im.emit(ctx, models.TaskEventUpdated, updated); err != nil {
+		return nil, err
 	}
 
 	return updated, nil
 }
 
-func (im *impl) Delete(ctx context.Context, id string) error {
+// updateTx runs update and replaces the labels of the task in a transaction, labels are
+// left untouched if it's nil
+func (im *impl) updateTx(ctx context.Context, id uuid.UUID, m *mutation, labels []string, opts ...MutateTaskOptionFunc) (*models.Task, error) {
+	txOpts := []TxOptionFunc{}
+	if m.parent != nil {
+		// NOTE: moves of two tasks under each other can't see each other and form a cycle unless serialized
+		txOpts = append(txOpts, WithIsolation(sql.LevelSerializable))
+	}
+	var task *models.Task
+	err := im.WithTx(ctx, func(t Task) error {
+		txStore := t.(*impl)
+		updated, err := txStore.update(ctx, id, m, opts...)
+		if err != nil {
+			return err
+		}
+		if labels != nil {
+			if updated.Labels, err = txStore.setLabels(ctx, id, labels); err != nil {
+				return err
+			}
+		}
+		task = updated
+		return nil
+	}, txOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// setLabels replaces the labels attached to the task with the labels named names,
+// and returns the names sorted in the same order as labelsColumn
+func (im *impl) setLabels(ctx context.Context, id uuid.UUID, names []string) (pq.StringArray, error) {
+	sorted := pq.StringArray{}
+	for name := range uniqueStrings(names) {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	pks := []int{}
+	if err := im.q.Select(ctx, &pks, "SELECT pk FROM labels WHERE name=ANY($1)", sorted); err != nil {
+		return nil, err
+	}
+	if len(pks) != len(sorted) {
+		return nil, ErrUnknownLabel
+	}
+
+	s := "DELETE FROM task_labels WHERE task_pk=(SELECT pk FROM tasks WHERE id=$1) AND label_pk<>ALL($2)"
+	if _, err := im.q.Exec(ctx, s, id, pq.Array(pks)); err != nil {
+		return nil, err
+	}
+	s = "INSERT INTO task_labels (task_pk, label_pk)\n" +
+		"SELECT pk, unnest($2::INTEGER[]) FROM tasks WHERE id=$1 ON CONFLICT DO NOTHING"
+	if _, err := im.q.Exec(ctx, s, id, pq.Array(pks)); err != nil {
+		return nil, err
+	}
+	return sorted, nil
+}
+
+// mutateFailure tells why a conditional mutation on the task affected no row
+func (im *impl) mutateFailure(ctx context.Context, id uuid.UUID, m *mutation, opts ...MutateTaskOptionFunc) error {
+	task, err := im.Get(ctx, id.String())
+	if err != nil {
+		return err
+	}
+	if err := m.check(task, opts...); err != nil {
+		return err
+	}
+	// NOTE: the task met every precondition when it was read again, it must be changed in between
+	return ErrConcurrentModification
+}
+
+func (im *impl) Delete(ctx context.Context, id string, opts ...MutateTaskOptionFunc) error {
 	parsedID, err := uuid.Parse(id)
 	if err != nil {
 		return ErrInvalidID
 	}
 
 	now := timeNow().UTC()
-	s := "UPDATE tasks SET deleted_at=$1 WHERE id=$2"
-	_, err = im.db.Exec(s, now, parsedID)
+	return im.WithTx(ctx, func(t Task) error {
+		txStore := t.(*impl)
+		m := &mutation{deleted: new(bool)}
+		m.set("deleted_at", now)
+		s := fmt.Sprintf("UPDATE tasks SET %s, version=version+1 WHERE %s RETURNING %s", strings.Join(m.sets, ", "), m.where(parsedID, opts...), taskColumns)
+		task := &models.Task{}
+		if err := txStore.q.Get(ctx, task, s, m.args...); err != nil {
+			if err == sql.ErrNoRows {
+				return txStore.mutateFailure(ctx, parsedID, m, opts...)
+			}
+			return err
+		}
+
+		// NOTE: subtasks are deleted at the same time as the task, so that Restore can tell them apart
+		subtasks := []*models.Task{}
+		if err := txStore.q.Select(ctx, &subtasks, descendantsCTE+"UPDATE tasks SET deleted_at=$2, version=version+1 "+
+			"WHERE id IN (SELECT id FROM descendants) AND deleted_at IS NULL RETURNING "+taskColumns, parsedID, now); err != nil {
+			return err
+		}
+		return txStore.emit(ctx, models.TaskEventDeleted, append([]*models.Task{task}, subtasks...)...)
+	})
+}
+
+func (im *impl) Purge(ctx context.Context, id string, opts ...MutateTaskOptionFunc) error {
+	parsedID, err := uuid.Parse(id)
 	if err != nil {
-		return err
+		return ErrInvalidID
 	}
-	return nil
+
+	return im.WithTx(ctx, func(t Task) error {
+		txStore := t.(*impl)
+		// NOTE: subtasks are removed along with the task by the foreign key, the ones which aren't
+		// deleted yet are told deleted as well as the task
+		live := []*models.Task{}
+		s := descendantsCTE + "SELECT " + taskColumns + " FROM tasks\n" +
+			"WHERE (id=$1 OR id IN (SELECT id FROM descendants)) AND deleted_at IS NULL ORDER BY id<>$1"
+		if err := txStore.q.Select(ctx, &live, s, parsedID); err != nil {
+			return err
+		}
+
+		m := &mutation{}
+		s = "DELETE FROM tasks WHERE " + m.where(parsedID, opts...)
+		res, err := txStore.q.Exec(ctx, s, m.args...)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return txStore.mutateFailure(ctx, parsedID, m, opts...)
+		}
+		return txStore.emit(ctx, models.TaskEventDeleted, live...)
+	})
+}
+
+func (im *impl) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	// NOTE: purge in batches so that a large backlog doesn't hold locks on too many rows at once.
+	// Subtasks are removed along with the task by the foreign key, so a task waits until none of its
+	// subtasks is live or deleted after the time.
+	s := "DELETE FROM tasks WHERE pk IN (SELECT t.pk FROM tasks t WHERE t.deleted_at < $1 AND NOT EXISTS (\n" +
+		"WITH RECURSIVE descendants AS (\n" +
+		"SELECT id, deleted_at FROM tasks WHERE parent_id=t.id\n" +
+		"UNION SELECT c.id, c.deleted_at FROM tasks c JOIN descendants d ON c.parent_id=d.id)\n" +
+		"SELECT 1 FROM descendants WHERE deleted_at IS NULL OR deleted_at >= $1)\n" +
+		"LIMIT $2)"
+	total := 0
+	for {
+		res, err := im.q.Exec(ctx, s, before, purgeBatchSize)
+		if err != nil {
+			return total, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += int(n)
+		if n < purgeBatchSize {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+
+	// NOTE: the room left by the tasks purged is taken back by spreading the positions grown long
+	if total > 0 {
+		if err := im.compactPositions(ctx); err != nil {
+			return total, err
+		}
+	}
+	return total, nil
 }
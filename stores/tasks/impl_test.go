@@ -4,19 +4,21 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/types"
 	"github.com/lib/pq"
-	migrate "github.com/rubenv/sql-migrate"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
-	bdocker "github.com/chihkaiyu/task-todo-api/base/docker"
+	"github.com/chihkaiyu/task-todo-api/base/lexorank"
 	"github.com/chihkaiyu/task-todo-api/models"
 	"github.com/chihkaiyu/task-todo-api/services/postgres"
+	"github.com/chihkaiyu/task-todo-api/stores/storetest"
 )
 
 var (
@@ -36,10 +38,8 @@ func (m *mockFuncs) timeNow() time.Time {
 }
 
 type taskSuite struct {
-	suite.Suite
-	taskStore    *impl
-	db           *sqlx.DB
-	postgresPort string
+	storetest.Suite
+	taskStore *impl
 
 	mockFuncs *mockFuncs
 }
@@ -48,25 +48,10 @@ func TestTaskSuite(t *testing.T) {
 	suite.Run(t, new(taskSuite))
 }
 
-func (s *taskSuite) SetupSuite() {
-	ports, err := bdocker.RunExternal([]string{"postgres"})
-	s.Require().NoError(err)
-	s.postgresPort = ports[0]
-}
-
-func (s *taskSuite) TearDownSuite() {
-	s.NoError(bdocker.RemoveExternal())
-}
-
 func (s *taskSuite) SetupTest() {
-	createDB("gogolook", s.postgresPort)
-	create("gogolook", s.postgresPort)
-
-	db, err := postgres.New(fmt.Sprintf("postgres://postgres@localhost:%s/gogolook?sslmode=disable", s.postgresPort))
-	s.Require().NoError(err)
-	s.db = db
+	s.Suite.SetupTest()
 	s.mockFuncs = new(mockFuncs)
-	s.taskStore = New(s.db).(*impl)
+	s.taskStore = New(s.DB).(*impl)
 
 	// mock functions
 	timeNow = s.mockFuncs.timeNow
@@ -74,51 +59,60 @@ func (s *taskSuite) SetupTest() {
 
 func (s *taskSuite) TearDownTest() {
 	s.mockFuncs.AssertExpectations(s.T())
+	s.Suite.TearDownTest()
+}
 
-	s.db.Close()
-	s.Require().NoError(bdocker.ClearPostgres(s.postgresPort))
+type createTaskOption struct {
+	id        uuid.UUID
+	name      string
+	status    models.TaskStatus
+	priority  int
+	dueAt     time.Time
+	parentID  uuid.UUID
+	createdAt time.Time
 }
 
-func createDB(name, port string) {
-	db, err := sql.Open("postgres", fmt.Sprintf("postgres://postgres@localhost:%s/?sslmode=disable", port))
-	if err != nil {
-		panic(err)
-	}
-	defer db.Close()
+type createTaskOptionFunc func(*createTaskOption)
 
-	_, err = db.Exec("CREATE DATABASE " + name)
-	if err != nil {
-		panic(err)
+func createWithID(id uuid.UUID) createTaskOptionFunc {
+	return func(cto *createTaskOption) {
+		cto.id = id
 	}
 }
 
-func create(name, port string) {
-	db, err := sql.Open("postgres", fmt.Sprintf("postgres://postgres@localhost:%s/%s?sslmode=disable", port, name))
-	if err != nil {
-		panic(err)
+func createWithName(name string) createTaskOptionFunc {
+	return func(cto *createTaskOption) {
+		cto.name = name
 	}
-	defer db.Close()
+}
 
-	migrations := &migrate.FileMigrationSource{
-		Dir: "../../infra/databases/api/migrations",
+func createWithStatus(status models.TaskStatus) createTaskOptionFunc {
+	return func(cto *createTaskOption) {
+		cto.status = status
 	}
+}
 
-	_, err = migrate.Exec(db, "postgres", migrations, migrate.Up)
-	if err != nil {
-		fmt.Println(err)
-		panic(err)
+func createWithPriority(priority int) createTaskOptionFunc {
+	return func(cto *createTaskOption) {
+		cto.priority = priority
 	}
 }
 
-type createTaskOption struct {
-	id uuid.UUID
+func createWithDueAt(dueAt time.Time) createTaskOptionFunc {
+	return func(cto *createTaskOption) {
+		cto.dueAt = dueAt
+	}
 }
 
-type createTaskOptionFunc func(*createTaskOption)
+func createWithParent(parentID uuid.UUID) createTaskOptionFunc {
+	return func(cto *createTaskOption) {
+		cto.parentID = parentID
+	}
+}
 
-func createWithID(id uuid.UUID) createTaskOptionFunc {
+func createWithCreatedAt(createdAt time.Time) createTaskOptionFunc {
 	return func(cto *createTaskOption) {
-		cto.id = id
+		cto.createdAt = createdAt
 	}
 }
 
@@ -126,7 +120,7 @@ func (s *taskSuite) createTask(opts ...createTaskOptionFunc) *models.Task {
 	task := &models.Task{
 		ID:        mockUUID,
 		Name:      "mock-task-name",
-		Status:    0,
+		Status:    models.TaskStatusTodo,
 		CreatedAt: mockNow,
 		UpdatedAt: mockNow,
 		DeletedAt: pq.NullTime{},
@@ -139,16 +133,55 @@ func (s *taskSuite) createTask(opts ...createTaskOptionFunc) *models.Task {
 	if opt.id != uuid.Nil {
 		task.ID = opt.id
 	}
+	if opt.name != "" {
+		task.Name = opt.name
+	}
+	if opt.status != "" {
+		task.Status = opt.status
+	}
+	task.Priority = opt.priority
+	if opt.parentID != uuid.Nil {
+		task.ParentID = uuid.NullUUID{UUID: opt.parentID, Valid: true}
+	}
+	if !opt.dueAt.IsZero() {
+		task.DueAt = pq.NullTime{Time: opt.dueAt, Valid: true}
+	}
+	if !opt.createdAt.IsZero() {
+		task.CreatedAt = opt.createdAt
+		task.UpdatedAt = opt.createdAt
+	}
+
+	// NOTE: tasks are placed at the end in the order they're created like the ones created by the store
+	last := ""
+	s.Require().NoError(s.DB.Get(&last, "SELECT COALESCE(MAX(position), '') FROM tasks"))
+	position, err := lexorank.Between(last, "")
+	s.Require().NoError(err)
+	task.Position = position
 
-	insertSQL := "INSERT INTO tasks (id, name, status) VALUES (:id, :name, :status)"
-	_, err := s.db.NamedExec(insertSQL, task)
+	insertSQL := "INSERT INTO tasks (id, parent_id, name, status, priority, due_at, created_at, updated_at, position)\n" +
+		"VALUES (:id, :parent_id, :name, :status, :priority, :due_at, :created_at, :updated_at, :position)"
+	_, err = s.DB.NamedExec(insertSQL, task)
 	s.Require().NoError(err)
 	return task
 }
 
+func (s *taskSuite) createLabels(names ...string) {
+	for _, name := range names {
+		_, err := s.DB.Exec("INSERT INTO labels (id, name) VALUES ($1, $2)", uuid.New(), name)
+		s.Require().NoError(err)
+	}
+}
+
+func (s *taskSuite) attachLabels(id uuid.UUID, names ...string) {
+	attachSQL := "INSERT INTO task_labels (task_pk, label_pk)\n" +
+		"SELECT t.pk, l.pk FROM tasks t, labels l WHERE t.id=$1 AND l.name=ANY($2)"
+	_, err := s.DB.Exec(attachSQL, id, pq.Array(names))
+	s.Require().NoError(err)
+}
+
 func (s *taskSuite) deleteTask(id uuid.UUID) {
 	deleteSQL := "UPDATE tasks SET deleted_at=$1 WHERE id=$2"
-	_, err := s.db.Exec(deleteSQL, mockNow, id)
+	_, err := s.DB.Exec(deleteSQL, mockNow, id)
 	s.Require().NoError(err)
 }
 
@@ -166,7 +199,7 @@ func (s *taskSuite) TestGet() {
 		{
 			desc:   "not found",
 			id:     mockUUID2.String(),
-			expErr: sql.ErrNoRows,
+			expErr: ErrTaskNotFound,
 		},
 		{
 			desc:   "invalid id",
@@ -197,17 +230,81 @@ func (s *taskSuite) TestGet() {
 }
 
 func (s *taskSuite) TestCreate() {
+	dueAt := mockNow.Add(24 * time.Hour).Truncate(time.Microsecond)
+
 	tests := []struct {
 		desc     string
 		mockFunc func()
-		name     string
+		params   *models.CreateTaskParams
+		expErr   error
 	}{
 		{
 			desc: "create normally",
 			mockFunc: func() {
 				s.mockFuncs.On("timeNow").Return(mockNow).Once()
 			},
-			name: "mock-task-name",
+			params: &models.CreateTaskParams{
+				Name: "mock-task-name",
+			},
+		},
+		{
+			desc: "create with labels",
+			mockFunc: func() {
+				s.createLabels("urgent", "backend")
+				s.mockFuncs.On("timeNow").Return(mockNow).Once()
+			},
+			params: &models.CreateTaskParams{
+				Name:   "mock-task-name",
+				Labels: []string{"urgent", "backend", "urgent"},
+			},
+		},
+		{
+			desc: "create with unknown label",
+			mockFunc: func() {
+				s.createLabels("backend")
+				s.mockFuncs.On("timeNow").Return(mockNow).Once()
+			},
+			params: &models.CreateTaskParams{
+				Name:   "mock-task-name",
+				Labels: []string{"backend", "frontend"},
+			},
+			expErr: ErrUnknownLabel,
+		},
+		{
+			desc: "create subtask",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow).Once()
+			},
+			params: &models.CreateTaskParams{
+				Name:     "mock-subtask-name",
+				ParentID: &mockUUID,
+			},
+		},
+		{
+			desc: "create under deleted parent",
+			mockFunc: func() {
+				s.createTask()
+				s.deleteTask(mockUUID)
+				s.mockFuncs.On("timeNow").Return(mockNow).Once()
+			},
+			params: &models.CreateTaskParams{
+				Name:     "mock-subtask-name",
+				ParentID: &mockUUID,
+			},
+			expErr: ErrParentNotFound,
+		},
+		{
+			desc: "create with description, priority and due date",
+			mockFunc: func() {
+				s.mockFuncs.On("timeNow").Return(mockNow).Once()
+			},
+			params: &models.CreateTaskParams{
+				Name:        "mock-task-name",
+				Description: "# mock\n- [ ] description",
+				Priority:    3,
+				DueAt:       &dueAt,
+			},
 		},
 	}
 
@@ -217,7 +314,16 @@ func (s *taskSuite) TestCreate() {
 
 		test.mockFunc()
 
-		expected, err := s.taskStore.Create(mockCTX, test.name)
+		expected, err := s.taskStore.Create(mockCTX, test.params)
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+			n := 0
+			s.Require().NoError(s.DB.Get(&n, "SELECT COUNT(*) FROM tasks WHERE name=$1", test.params.Name), test.desc)
+			s.Require().Zero(n, test.desc)
+
+			s.TearDownTest()
+			continue
+		}
 		s.Require().NoError(err, test.desc)
 
 		act, err := s.taskStore.Get(mockCTX, expected.ID.String())
@@ -225,7 +331,15 @@ func (s *taskSuite) TestCreate() {
 
 		s.Require().Equal(expected.ID, act.ID, test.desc)
 		s.Require().Equal(expected.Name, act.Name, test.desc)
+		s.Require().Equal(expected.Description, act.Description, test.desc)
 		s.Require().Equal(expected.Status, act.Status, test.desc)
+		s.Require().Equal(expected.Priority, act.Priority, test.desc)
+		s.Require().Equal(expected.DueAt.Valid, act.DueAt.Valid, test.desc)
+		s.Require().True(expected.DueAt.Time.Equal(act.DueAt.Time), test.desc)
+		s.Require().False(act.CompletedAt.Valid, test.desc)
+		s.Require().Equal(expected.Labels, act.Labels, test.desc)
+		s.Require().Equal(expected.ParentID, act.ParentID, test.desc)
+		s.Require().Equal(expected.Position, act.Position, test.desc)
 
 		s.TearDownTest()
 	}
@@ -237,6 +351,9 @@ func (s *taskSuite) TestList() {
 		mockFunc func()
 		opts     []ListTaskOptionFunc
 		expNum   int
+		expNames []string
+		expNext  bool
+		expErr   error
 	}{
 		{
 			desc: "list normally",
@@ -276,6 +393,177 @@ func (s *taskSuite) TestList() {
 			opts:   []ListTaskOptionFunc{WithDeleted()},
 			expNum: 5,
 		},
+		{
+			desc: "list with limit",
+			mockFunc: func() {
+				for i := 0; i < 3; i++ {
+					s.createTask(createWithID(uuid.New()), createWithName(fmt.Sprintf("task-%d", i)), createWithCreatedAt(mockNow.Add(time.Duration(i)*time.Minute)))
+				}
+			},
+			opts:     []ListTaskOptionFunc{WithLimit(2)},
+			expNum:   2,
+			expNames: []string{"task-0", "task-1"},
+			expNext:  true,
+		},
+		{
+			desc: "list with status",
+			mockFunc: func() {
+				s.createTask(createWithID(uuid.New()), createWithStatus(models.TaskStatusDone))
+				s.createTask(createWithID(uuid.New()), createWithStatus(models.TaskStatusTodo))
+			},
+			opts:   []ListTaskOptionFunc{WithStatus(models.TaskStatusDone)},
+			expNum: 1,
+		},
+		{
+			desc: "list with name contains",
+			mockFunc: func() {
+				s.createTask(createWithID(uuid.New()), createWithName("buy MILK"))
+				s.createTask(createWithID(uuid.New()), createWithName("buy eggs"))
+				s.createTask(createWithID(uuid.New()), createWithName("100% milk_tea"))
+			},
+			opts:     []ListTaskOptionFunc{WithNameContains("milk"), WithSort("name")},
+			expNum:   2,
+			expNames: []string{"100% milk_tea", "buy MILK"},
+		},
+		{
+			desc: "list with escaped name contains",
+			mockFunc: func() {
+				s.createTask(createWithID(uuid.New()), createWithName("100% milk_tea"))
+				s.createTask(createWithID(uuid.New()), createWithName("1000 milkytea"))
+			},
+			opts:     []ListTaskOptionFunc{WithNameContains("0% milk_")},
+			expNum:   1,
+			expNames: []string{"100% milk_tea"},
+		},
+		{
+			desc: "list with descending sort",
+			mockFunc: func() {
+				for i := 0; i < 3; i++ {
+					s.createTask(createWithID(uuid.New()), createWithName(fmt.Sprintf("task-%d", i)), createWithCreatedAt(mockNow.Add(time.Duration(i)*time.Minute)))
+				}
+			},
+			opts:     []ListTaskOptionFunc{WithSort("-created_at")},
+			expNum:   3,
+			expNames: []string{"task-2", "task-1", "task-0"},
+		},
+		{
+			desc: "list with search",
+			mockFunc: func() {
+				s.createTask(createWithID(uuid.New()), createWithName("buy milk and tea"))
+				s.createTask(createWithID(uuid.New()), createWithName("Milkshake"))
+				s.createTask(createWithID(uuid.New()), createWithName("buy coffee"))
+			},
+			opts:     []ListTaskOptionFunc{WithSearch("MILK")},
+			expNum:   2,
+			expNames: []string{"Milkshake", "buy milk and tea"},
+		},
+		{
+			desc: "list with search of multiple words",
+			mockFunc: func() {
+				s.createTask(createWithID(uuid.New()), createWithName("buy milk and tea"))
+				s.createTask(createWithID(uuid.New()), createWithName("buy coffee"))
+			},
+			opts:     []ListTaskOptionFunc{WithSearch("bu & te:*|")},
+			expNum:   1,
+			expNames: []string{"buy milk and tea"},
+		},
+		{
+			desc: "list with search sorted by name",
+			mockFunc: func() {
+				s.createTask(createWithID(uuid.New()), createWithName("milk b"))
+				s.createTask(createWithID(uuid.New()), createWithName("milk milk a"))
+			},
+			opts:     []ListTaskOptionFunc{WithSearch("milk"), WithSort("name")},
+			expNum:   2,
+			expNames: []string{"milk b", "milk milk a"},
+		},
+		{
+			desc:     "invalid sort",
+			mockFunc: func() {},
+			opts:     []ListTaskOptionFunc{WithSort("status")},
+			expErr:   ErrInvalidSort,
+		},
+		{
+			desc: "list with any of labels",
+			mockFunc: func() {
+				s.createLabels("backend", "urgent", "frontend")
+				s.attachLabels(s.createTask(createWithID(uuid.New()), createWithName("both")).ID, "backend", "urgent")
+				s.attachLabels(s.createTask(createWithID(uuid.New()), createWithName("backend")).ID, "backend")
+				s.attachLabels(s.createTask(createWithID(uuid.New()), createWithName("frontend")).ID, "frontend")
+				s.createTask(createWithID(uuid.New()), createWithName("none"))
+			},
+			opts:     []ListTaskOptionFunc{WithLabels([]string{"backend", "urgent"}, false), WithSort("name")},
+			expNum:   2,
+			expNames: []string{"backend", "both"},
+		},
+		{
+			desc: "list with all of labels",
+			mockFunc: func() {
+				s.createLabels("backend", "urgent", "frontend")
+				s.attachLabels(s.createTask(createWithID(uuid.New()), createWithName("both")).ID, "backend", "urgent")
+				s.attachLabels(s.createTask(createWithID(uuid.New()), createWithName("backend")).ID, "backend")
+				s.attachLabels(s.createTask(createWithID(uuid.New()), createWithName("frontend")).ID, "frontend")
+			},
+			opts:     []ListTaskOptionFunc{WithLabels([]string{"backend", "urgent", "backend"}, true)},
+			expNum:   1,
+			expNames: []string{"both"},
+		},
+		{
+			desc: "list with priority",
+			mockFunc: func() {
+				s.createTask(createWithID(uuid.New()), createWithName("low"), createWithPriority(1))
+				s.createTask(createWithID(uuid.New()), createWithName("high"), createWithPriority(3))
+			},
+			opts:     []ListTaskOptionFunc{WithPriority(3)},
+			expNum:   1,
+			expNames: []string{"high"},
+		},
+		{
+			desc: "list with due range",
+			mockFunc: func() {
+				s.createTask(createWithID(uuid.New()), createWithName("yesterday"), createWithDueAt(mockNow.Add(-24*time.Hour)))
+				s.createTask(createWithID(uuid.New()), createWithName("today"), createWithDueAt(mockNow))
+				s.createTask(createWithID(uuid.New()), createWithName("tomorrow"), createWithDueAt(mockNow.Add(24*time.Hour)))
+				s.createTask(createWithID(uuid.New()), createWithName("someday"))
+			},
+			opts:     []ListTaskOptionFunc{WithDueAfter(mockNow.Add(-time.Hour)), WithDueBefore(mockNow.Add(24 * time.Hour))},
+			expNum:   1,
+			expNames: []string{"today"},
+		},
+		{
+			desc: "list sorted by due date",
+			mockFunc: func() {
+				s.createTask(createWithID(uuid.New()), createWithName("someday"))
+				s.createTask(createWithID(uuid.New()), createWithName("tomorrow"), createWithDueAt(mockNow.Add(24*time.Hour)))
+				s.createTask(createWithID(uuid.New()), createWithName("today"), createWithDueAt(mockNow))
+			},
+			opts:     []ListTaskOptionFunc{WithSort("due_at")},
+			expNum:   3,
+			expNames: []string{"today", "tomorrow", "someday"},
+		},
+		{
+			desc: "list sorted by descending priority",
+			mockFunc: func() {
+				s.createTask(createWithID(uuid.New()), createWithName("low"), createWithPriority(1))
+				s.createTask(createWithID(uuid.New()), createWithName("high"), createWithPriority(3))
+				s.createTask(createWithID(uuid.New()), createWithName("none"))
+			},
+			opts:     []ListTaskOptionFunc{WithSort("-priority")},
+			expNum:   3,
+			expNames: []string{"high", "low", "none"},
+		},
+		{
+			desc:     "sort by rank without search",
+			mockFunc: func() {},
+			opts:     []ListTaskOptionFunc{WithSort("-rank")},
+			expErr:   ErrInvalidSort,
+		},
+		{
+			desc:     "invalid cursor",
+			mockFunc: func() {},
+			opts:     []ListTaskOptionFunc{WithCursor("invalid-cursor")},
+			expErr:   ErrInvalidCursor,
+		},
 	}
 
 	s.TearDownTest()
@@ -284,11 +572,62 @@ func (s *taskSuite) TestList() {
 
 		test.mockFunc()
 
-		tasks, err := s.taskStore.List(mockCTX, test.opts...)
-		s.Require().NoError(err, test.desc)
-		s.Require().Len(tasks, test.expNum, test.desc)
+		tasks, next, err := s.taskStore.List(mockCTX, test.opts...)
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			s.Require().Len(tasks, test.expNum, test.desc)
+			s.Require().Equal(test.expNext, next != "", test.desc)
+			if test.expNames != nil {
+				names := make([]string, len(tasks))
+				for i, t := range tasks {
+					names[i] = t.Name
+				}
+				s.Require().Equal(test.expNames, names, test.desc)
+			}
+		}
+
+		s.TearDownTest()
+	}
+}
 
+func (s *taskSuite) TestListPagination() {
+	sorts := []string{"", "position", "-position", "created_at", "-created_at", "updated_at", "name", "-name"}
+
+	for _, sort := range sorts {
 		s.TearDownTest()
+		s.SetupTest()
+
+		// NOTE: duplicated names and timestamps make sure pk breaks the ties
+		for i := 0; i < 7; i++ {
+			s.createTask(createWithID(uuid.New()), createWithName(fmt.Sprintf("task-%d", i%3)), createWithCreatedAt(mockNow.Add(time.Duration(i%4)*time.Minute)))
+		}
+		all, next, err := s.taskStore.List(mockCTX, WithSort(sort))
+		s.Require().NoError(err, sort)
+		s.Require().Len(all, 7, sort)
+		s.Require().Empty(next, sort)
+
+		paged := []*models.Task{}
+		cursor := ""
+		for {
+			page, next, err := s.taskStore.List(mockCTX, WithSort(sort), WithLimit(3), WithCursor(cursor))
+			s.Require().NoError(err, sort)
+			paged = append(paged, page...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		s.Require().Len(paged, len(all), sort)
+		for i := range all {
+			s.Require().Equal(all[i].ID, paged[i].ID, sort)
+		}
+
+		_, _, err = s.taskStore.List(mockCTX, WithSort("name"), WithCursor(cursor))
+		if sort != "name" && cursor != "" {
+			s.Require().EqualError(err, ErrInvalidCursor.Error(), sort)
+		}
 	}
 }
 
@@ -298,6 +637,7 @@ func (s *taskSuite) TestPut() {
 		mockFunc func()
 		id       string
 		params   *models.PutTaskParams
+		opts     []MutateTaskOptionFunc
 		expTask  *models.Task
 		expErr   error
 	}{
@@ -310,27 +650,112 @@ func (s *taskSuite) TestPut() {
 			id: mockUUID.String(),
 			params: &models.PutTaskParams{
 				Name:   "updated-task-name",
-				Status: 1,
+				Status: models.TaskStatusDone,
+			},
+			expTask: &models.Task{
+				ID:        mockUUID,
+				Name:      "updated-task-name",
+				Status:    models.TaskStatusDone,
+				UpdatedAt: mockNow.Add(7 * time.Minute),
+				Version:   2,
+			},
+			expErr: nil,
+		},
+		{
+			desc: "put with matched version",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id: mockUUID.String(),
+			params: &models.PutTaskParams{
+				Name:   "updated-task-name",
+				Status: models.TaskStatusDone,
+			},
+			opts: []MutateTaskOptionFunc{WithVersion(1)},
+			expTask: &models.Task{
+				ID:        mockUUID,
+				Name:      "updated-task-name",
+				Status:    models.TaskStatusDone,
+				UpdatedAt: mockNow.Add(7 * time.Minute),
+				Version:   2,
+			},
+			expErr: nil,
+		},
+		{
+			desc: "put with stale version",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id: mockUUID.String(),
+			params: &models.PutTaskParams{
+				Name:   "updated-task-name",
+				Status: models.TaskStatusDone,
+			},
+			opts:    []MutateTaskOptionFunc{WithVersion(3)},
+			expTask: nil,
+			expErr:  ErrVersionMismatch,
+		},
+		{
+			desc: "put with legacy default status",
+			mockFunc: func() {
+				s.createTask(createWithStatus(models.TaskStatusDone))
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id: mockUUID.String(),
+			params: &models.PutTaskParams{
+				Name: "updated-task-name",
 			},
 			expTask: &models.Task{
 				ID:        mockUUID,
 				Name:      "updated-task-name",
-				Status:    1,
+				Status:    models.TaskStatusTodo,
 				UpdatedAt: mockNow.Add(7 * time.Minute),
+				Version:   2,
 			},
 			expErr: nil,
 		},
+		{
+			desc: "put with invalid status transition",
+			mockFunc: func() {
+				s.createTask(createWithStatus(models.TaskStatusArchived))
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id: mockUUID.String(),
+			params: &models.PutTaskParams{
+				Name:   "updated-task-name",
+				Status: models.TaskStatusDone,
+			},
+			expTask: nil,
+			expErr:  ErrInvalidStatusTransition,
+		},
 		{
 			desc:     "invalid uuid",
 			mockFunc: func() {},
 			id:       "mock-invalid-id",
 			params: &models.PutTaskParams{
 				Name:   "updated-task-name",
-				Status: 1,
+				Status: models.TaskStatusDone,
 			},
 			expTask: nil,
 			expErr:  ErrInvalidID,
 		},
+		{
+			desc: "put deleted task",
+			mockFunc: func() {
+				s.createTask()
+				s.deleteTask(mockUUID)
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id: mockUUID.String(),
+			params: &models.PutTaskParams{
+				Name:   "updated-task-name",
+				Status: models.TaskStatusDone,
+			},
+			expTask: nil,
+			expErr:  ErrTaskDeleted,
+		},
 		{
 			desc: "put non-exist task",
 			mockFunc: func() {
@@ -340,7 +765,7 @@ func (s *taskSuite) TestPut() {
 			id: mockUUID2.String(),
 			params: &models.PutTaskParams{
 				Name:   "updated-task-name",
-				Status: 1,
+				Status: models.TaskStatusDone,
 			},
 			expTask: nil,
 			expErr:  ErrTaskNotFound,
@@ -352,7 +777,7 @@ func (s *taskSuite) TestPut() {
 		s.SetupTest()
 
 		test.mockFunc()
-		updated, err := s.taskStore.Put(mockCTX, test.id, test.params)
+		updated, err := s.taskStore.Put(mockCTX, test.id, test.params, test.opts...)
 		if test.expErr != nil {
 			s.Require().EqualError(err, test.expErr.Error(), test.desc)
 		} else {
@@ -362,37 +787,307 @@ func (s *taskSuite) TestPut() {
 			s.Require().Equal(test.expTask.Name, updated.Name, test.desc)
 			s.Require().Equal(test.expTask.Status, updated.Status, test.desc)
 			s.Require().Equal(test.expTask.UpdatedAt, updated.UpdatedAt, test.desc)
+			s.Require().Equal(test.expTask.Version, updated.Version, test.desc)
 		}
 
 		s.TearDownTest()
 	}
 }
 
-func (s *taskSuite) TestDelete() {
+func (s *taskSuite) TestPatch() {
+	updatedName := "updated-task-name"
+	updatedStatus := models.TaskStatusDone
+	updatedDescription := "updated-description"
+	updatedPriority := 2
+	updatedDueAt := mockNow.Add(48 * time.Hour)
+	todoStatus := models.TaskStatusTodo
+
 	tests := []struct {
 		desc     string
 		mockFunc func()
 		id       string
-		deleted  bool
+		params   *models.PatchTaskParams
+		expTask  *models.Task
 		expErr   error
 	}{
 		{
-			desc: "delete normally",
+			desc: "patch name only",
 			mockFunc: func() {
 				s.createTask()
 				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
 			},
-			id:      mockUUID.String(),
-			deleted: true,
-			expErr:  nil,
+			id: mockUUID.String(),
+			params: &models.PatchTaskParams{
+				Name: &updatedName,
+			},
+			expTask: &models.Task{
+				ID:        mockUUID,
+				Name:      "updated-task-name",
+				Status:    models.TaskStatusTodo,
+				UpdatedAt: mockNow.Add(7 * time.Minute),
+			},
+			expErr: nil,
 		},
 		{
-			desc: "invalid id",
+			desc: "patch status only",
 			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
 			},
-			id:      "mock-invalid-id",
-			deleted: false,
-			expErr:  ErrInvalidID,
+			id: mockUUID.String(),
+			params: &models.PatchTaskParams{
+				Status: &updatedStatus,
+			},
+			expTask: &models.Task{
+				ID:          mockUUID,
+				Name:        "mock-task-name",
+				Status:      models.TaskStatusDone,
+				CompletedAt: pq.NullTime{Time: mockNow.Add(7 * time.Minute), Valid: true},
+				UpdatedAt:   mockNow.Add(7 * time.Minute),
+			},
+			expErr: nil,
+		},
+		{
+			desc: "patch status to done completes task",
+			mockFunc: func() {
+				s.createTask(createWithDueAt(mockNow))
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id: mockUUID.String(),
+			params: &models.PatchTaskParams{
+				Status:     &updatedStatus,
+				Priority:   &updatedPriority,
+				ClearDueAt: true,
+			},
+			expTask: &models.Task{
+				ID:          mockUUID,
+				Name:        "mock-task-name",
+				Status:      models.TaskStatusDone,
+				Priority:    updatedPriority,
+				CompletedAt: pq.NullTime{Time: mockNow.Add(7 * time.Minute), Valid: true},
+				UpdatedAt:   mockNow.Add(7 * time.Minute),
+			},
+			expErr: nil,
+		},
+		{
+			desc: "patch status from done clears completion",
+			mockFunc: func() {
+				s.createTask(createWithStatus(models.TaskStatusDone))
+				_, err := s.DB.Exec("UPDATE tasks SET completed_at=$1 WHERE id=$2", mockNow, mockUUID)
+				s.Require().NoError(err)
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id: mockUUID.String(),
+			params: &models.PatchTaskParams{
+				Status:      &todoStatus,
+				Description: &updatedDescription,
+				DueAt:       &updatedDueAt,
+			},
+			expTask: &models.Task{
+				ID:          mockUUID,
+				Name:        "mock-task-name",
+				Description: updatedDescription,
+				Status:      models.TaskStatusTodo,
+				DueAt:       pq.NullTime{Time: updatedDueAt, Valid: true},
+				UpdatedAt:   mockNow.Add(7 * time.Minute),
+			},
+			expErr: nil,
+		},
+		{
+			desc: "patch parent to subtask",
+			mockFunc: func() {
+				s.createTask()
+				s.createTask(createWithID(mockUUID2), createWithParent(mockUUID))
+			},
+			id: mockUUID.String(),
+			params: &models.PatchTaskParams{
+				ParentID: &mockUUID2,
+			},
+			expTask: nil,
+			expErr:  ErrTaskCycle,
+		},
+		{
+			desc: "patch parent to itself",
+			mockFunc: func() {
+				s.createTask()
+			},
+			id: mockUUID.String(),
+			params: &models.PatchTaskParams{
+				ParentID: &mockUUID,
+			},
+			expTask: nil,
+			expErr:  ErrTaskCycle,
+		},
+		{
+			desc: "patch labels only",
+			mockFunc: func() {
+				s.createLabels("backend", "urgent")
+				s.createTask()
+				s.attachLabels(mockUUID, "backend")
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id: mockUUID.String(),
+			params: &models.PatchTaskParams{
+				Labels: []string{"urgent"},
+			},
+			expTask: &models.Task{
+				ID:        mockUUID,
+				Name:      "mock-task-name",
+				Status:    models.TaskStatusTodo,
+				Labels:    pq.StringArray{"urgent"},
+				UpdatedAt: mockNow.Add(7 * time.Minute),
+			},
+			expErr: nil,
+		},
+		{
+			desc: "patch unknown label",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id: mockUUID.String(),
+			params: &models.PatchTaskParams{
+				Labels: []string{"urgent"},
+			},
+			expTask: nil,
+			expErr:  ErrUnknownLabel,
+		},
+		{
+			desc: "empty patch",
+			mockFunc: func() {
+				s.createTask()
+			},
+			id:     mockUUID.String(),
+			params: &models.PatchTaskParams{},
+			expTask: &models.Task{
+				ID:     mockUUID,
+				Name:   "mock-task-name",
+				Status: models.TaskStatusTodo,
+			},
+			expErr: nil,
+		},
+		{
+			desc:     "invalid uuid",
+			mockFunc: func() {},
+			id:       "mock-invalid-id",
+			params: &models.PatchTaskParams{
+				Name: &updatedName,
+			},
+			expTask: nil,
+			expErr:  ErrInvalidID,
+		},
+		{
+			desc: "patch deleted task",
+			mockFunc: func() {
+				s.createTask()
+				s.deleteTask(mockUUID)
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id: mockUUID.String(),
+			params: &models.PatchTaskParams{
+				Name: &updatedName,
+			},
+			expTask: nil,
+			expErr:  ErrTaskDeleted,
+		},
+		{
+			desc: "patch non-exist task",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id: mockUUID2.String(),
+			params: &models.PatchTaskParams{
+				Name: &updatedName,
+			},
+			expTask: nil,
+			expErr:  ErrTaskNotFound,
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		test.mockFunc()
+		updated, err := s.taskStore.Patch(mockCTX, test.id, test.params)
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+
+			s.Require().Equal(test.expTask.ID, updated.ID, test.desc)
+			s.Require().Equal(test.expTask.Name, updated.Name, test.desc)
+			s.Require().Equal(test.expTask.Status, updated.Status, test.desc)
+			s.Require().Equal(test.expTask.Description, updated.Description, test.desc)
+			s.Require().Equal(test.expTask.Priority, updated.Priority, test.desc)
+			s.Require().Equal(test.expTask.DueAt.Valid, updated.DueAt.Valid, test.desc)
+			s.Require().WithinDuration(test.expTask.DueAt.Time, updated.DueAt.Time, time.Millisecond, test.desc)
+			s.Require().Equal(test.expTask.CompletedAt.Valid, updated.CompletedAt.Valid, test.desc)
+			s.Require().WithinDuration(test.expTask.CompletedAt.Time, updated.CompletedAt.Time, time.Millisecond, test.desc)
+			if test.expTask.Labels != nil {
+				s.Require().Equal(test.expTask.Labels, updated.Labels, test.desc)
+				act, err := s.taskStore.Get(mockCTX, test.id)
+				s.Require().NoError(err, test.desc)
+				s.Require().Equal(test.expTask.Labels, act.Labels, test.desc)
+			}
+			if !test.expTask.UpdatedAt.IsZero() {
+				s.Require().Equal(test.expTask.UpdatedAt, updated.UpdatedAt, test.desc)
+			}
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *taskSuite) TestDelete() {
+	tests := []struct {
+		desc     string
+		mockFunc func()
+		id       string
+		opts     []MutateTaskOptionFunc
+		deleted  bool
+		expErr   error
+	}{
+		{
+			desc: "delete normally",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id:      mockUUID.String(),
+			deleted: true,
+			expErr:  nil,
+		},
+		{
+			desc: "delete with matched version",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id:      mockUUID.String(),
+			opts:    []MutateTaskOptionFunc{WithVersion(1)},
+			deleted: true,
+			expErr:  nil,
+		},
+		{
+			desc: "delete with stale version",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id:      mockUUID.String(),
+			opts:    []MutateTaskOptionFunc{WithVersion(2)},
+			deleted: false,
+			expErr:  ErrVersionMismatch,
+		},
+		{
+			desc: "invalid id",
+			mockFunc: func() {
+			},
+			id:      "mock-invalid-id",
+			deleted: false,
+			expErr:  ErrInvalidID,
 		},
 		{
 			desc: "delete non-exist task",
@@ -402,7 +1097,18 @@ func (s *taskSuite) TestDelete() {
 			},
 			id:      mockUUID2.String(),
 			deleted: false,
-			expErr:  nil,
+			expErr:  ErrTaskNotFound,
+		},
+		{
+			desc: "delete deleted task",
+			mockFunc: func() {
+				s.createTask()
+				s.deleteTask(mockUUID)
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id:      mockUUID.String(),
+			deleted: false,
+			expErr:  ErrTaskDeleted,
 		},
 	}
 
@@ -411,7 +1117,7 @@ func (s *taskSuite) TestDelete() {
 		s.SetupTest()
 
 		test.mockFunc()
-		err := s.taskStore.Delete(mockCTX, test.id)
+		err := s.taskStore.Delete(mockCTX, test.id, test.opts...)
 		if test.expErr != nil {
 			s.Require().EqualError(err, test.expErr.Error(), test.desc)
 		} else {
@@ -421,9 +1127,883 @@ func (s *taskSuite) TestDelete() {
 				deleted, err := s.taskStore.Get(mockCTX, test.id)
 				s.Require().NoError(err, test.desc)
 				s.Require().NotNil(deleted.DeletedAt, test.desc)
+				// NOTE: postgres keeps microseconds only
+				s.Require().WithinDuration(mockNow.Add(7*time.Minute), deleted.DeletedAt.Time, time.Microsecond, test.desc)
 			}
 		}
 
 		s.TearDownTest()
 	}
 }
+
+func (s *taskSuite) TestRestore() {
+	tests := []struct {
+		desc     string
+		mockFunc func()
+		id       string
+		expErr   error
+	}{
+		{
+			desc: "restore normally",
+			mockFunc: func() {
+				s.createTask()
+				s.deleteTask(mockUUID)
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id:     mockUUID.String(),
+			expErr: nil,
+		},
+		{
+			desc: "restore task not deleted",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id:     mockUUID.String(),
+			expErr: ErrTaskNotDeleted,
+		},
+		{
+			desc: "restore non-exist task",
+			mockFunc: func() {
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			id:     mockUUID.String(),
+			expErr: ErrTaskNotFound,
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		test.mockFunc()
+		restored, err := s.taskStore.Restore(mockCTX, test.id)
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			s.Require().False(restored.DeletedAt.Valid, test.desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *taskSuite) TestPurge() {
+	tests := []struct {
+		desc     string
+		mockFunc func()
+		id       string
+		expErr   error
+	}{
+		{
+			desc: "purge normally",
+			mockFunc: func() {
+				s.createTask()
+			},
+			id:     mockUUID.String(),
+			expErr: nil,
+		},
+		{
+			desc: "purge deleted task",
+			mockFunc: func() {
+				s.createTask()
+				s.deleteTask(mockUUID)
+			},
+			id:     mockUUID.String(),
+			expErr: nil,
+		},
+		{
+			desc:     "purge non-exist task",
+			mockFunc: func() {},
+			id:       mockUUID.String(),
+			expErr:   ErrTaskNotFound,
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		test.mockFunc()
+		err := s.taskStore.Purge(mockCTX, test.id)
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			_, err := s.taskStore.Get(mockCTX, test.id)
+			s.Require().EqualError(err, ErrTaskNotFound.Error(), test.desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *taskSuite) TestPurgeDeleted() {
+	for i := 0; i < 3; i++ {
+		s.createTask(createWithID(uuid.New()))
+	}
+	for i := 0; i < 2; i++ {
+		t := s.createTask(createWithID(uuid.New()))
+		s.deleteTask(t.ID)
+	}
+
+	n, err := s.taskStore.PurgeDeleted(mockCTX, mockNow.Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Require().Equal(0, n)
+
+	n, err = s.taskStore.PurgeDeleted(mockCTX, mockNow.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().Equal(2, n)
+
+	tasks, _, err := s.taskStore.List(mockCTX, WithDeleted())
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 3)
+
+	// a deleted task waits for its subtasks, which are removed along with it
+	parent := s.createTask(createWithID(uuid.New()))
+	child := s.createTask(createWithID(uuid.New()), createWithParent(parent.ID))
+	grandchild := s.createTask(createWithID(uuid.New()), createWithParent(child.ID))
+	s.deleteTask(parent.ID)
+	s.deleteTask(child.ID)
+	n, err = s.taskStore.PurgeDeleted(mockCTX, mockNow.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().Equal(0, n)
+	_, err = s.taskStore.Get(mockCTX, grandchild.ID.String())
+	s.Require().NoError(err)
+
+	s.deleteTask(grandchild.ID)
+	n, err = s.taskStore.PurgeDeleted(mockCTX, mockNow.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().Equal(1, n)
+	tasks, _, err = s.taskStore.List(mockCTX, WithDeleted())
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 3)
+}
+
+func (s *taskSuite) TestBatch() {
+	name := "mock-batch-name"
+	done := models.TaskStatusDone
+
+	tests := []struct {
+		desc       string
+		mockFunc   func()
+		ops        []*models.BatchTaskOperation
+		atomic     bool
+		expErrs    []error
+		expTaskNum int
+		checkFunc  func()
+	}{
+		{
+			desc: "batch normally",
+			mockFunc: func() {
+				s.createTask()
+				s.createTask(createWithID(mockUUID2))
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Times(3)
+			},
+			ops: []*models.BatchTaskOperation{
+				{Op: models.BatchOpCreate, Name: &name},
+				{Op: models.BatchOpUpdate, ID: mockUUID.String(), Status: &done},
+				{Op: models.BatchOpDelete, ID: mockUUID2.String()},
+			},
+			atomic:     true,
+			expErrs:    []error{nil, nil, nil},
+			expTaskNum: 2,
+		},
+		{
+			desc: "atomic batch rolls back",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Times(2)
+			},
+			ops: []*models.BatchTaskOperation{
+				{Op: models.BatchOpCreate, Name: &name},
+				{Op: models.BatchOpDelete, ID: mockUUID2.String()},
+				{Op: models.BatchOpDelete, ID: mockUUID.String()},
+			},
+			atomic:     true,
+			expErrs:    []error{ErrBatchRolledBack, ErrTaskNotFound, ErrBatchSkipped},
+			expTaskNum: 1,
+		},
+		{
+			desc: "best-effort batch keeps succeeded operations",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Times(3)
+			},
+			ops: []*models.BatchTaskOperation{
+				{Op: models.BatchOpCreate, Name: &name},
+				{Op: models.BatchOpDelete, ID: mockUUID2.String()},
+				{Op: models.BatchOpUpdate, ID: mockUUID.String(), Status: &done, Version: new(int)},
+			},
+			atomic:     false,
+			expErrs:    []error{nil, ErrTaskNotFound, ErrVersionMismatch},
+			expTaskNum: 2,
+		},
+		{
+			desc: "batch update clears fields",
+			mockFunc: func() {
+				s.createTask(createWithID(mockUUID2))
+				s.createTask(createWithDueAt(mockNow), createWithParent(mockUUID2))
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			ops: []*models.BatchTaskOperation{
+				{Op: models.BatchOpUpdate, ID: mockUUID.String(), ClearDueAt: true, ClearParentID: true},
+			},
+			atomic:     true,
+			expErrs:    []error{nil},
+			expTaskNum: 2,
+			checkFunc: func() {
+				task, err := s.taskStore.Get(mockCTX, mockUUID.String())
+				s.Require().NoError(err)
+				s.Require().False(task.DueAt.Valid)
+				s.Require().False(task.ParentID.Valid)
+			},
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		test.mockFunc()
+		results, err := s.taskStore.Batch(mockCTX, test.ops, test.atomic)
+		s.Require().NoError(err, test.desc)
+		s.Require().Len(results, len(test.expErrs), test.desc)
+		for i, expErr := range test.expErrs {
+			if expErr != nil {
+				s.Require().EqualError(results[i].Err, expErr.Error(), test.desc)
+			} else {
+				s.Require().NoError(results[i].Err, test.desc)
+			}
+		}
+
+		tasks, _, err := s.taskStore.List(mockCTX)
+		s.Require().NoError(err, test.desc)
+		s.Require().Len(tasks, test.expTaskNum, test.desc)
+		if test.checkFunc != nil {
+			test.checkFunc()
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *taskSuite) TestWithTx() {
+	errMock := fmt.Errorf("mock error")
+	name := "mock-tx-name"
+
+	tests := []struct {
+		desc       string
+		mockFunc   func()
+		fn         func(calls *int) func(Task) error
+		opts       []TxOptionFunc
+		expErr     error
+		expCalls   int
+		expTaskNum int
+	}{
+		{
+			desc: "commit normally",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Times(2)
+			},
+			fn: func(calls *int) func(Task) error {
+				return func(t Task) error {
+					*calls++
+					if _, err := t.Create(mockCTX, &models.CreateTaskParams{Name: name}); err != nil {
+						return err
+					}
+					return t.Delete(mockCTX, mockUUID.String())
+				}
+			},
+			opts:       []TxOptionFunc{WithIsolation(sql.LevelSerializable)},
+			expCalls:   1,
+			expTaskNum: 1,
+		},
+		{
+			desc: "roll back on error",
+			mockFunc: func() {
+				s.createTask()
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			fn: func(calls *int) func(Task) error {
+				return func(t Task) error {
+					*calls++
+					if _, err := t.Create(mockCTX, &models.CreateTaskParams{Name: name}); err != nil {
+						return err
+					}
+					return errMock
+				}
+			},
+			expErr:     errMock,
+			expCalls:   1,
+			expTaskNum: 1,
+		},
+		{
+			desc:     "retry on serialization failure",
+			mockFunc: func() {},
+			fn: func(calls *int) func(Task) error {
+				return func(t Task) error {
+					*calls++
+					if *calls < 3 {
+						return &pq.Error{Code: "40001"}
+					}
+					return nil
+				}
+			},
+			expCalls: 3,
+		},
+		{
+			desc:     "give up after max retries",
+			mockFunc: func() {},
+			fn: func(calls *int) func(Task) error {
+				return func(t Task) error {
+					*calls++
+					return &pq.Error{Code: "40P01"}
+				}
+			},
+			opts:     []TxOptionFunc{WithMaxRetries(1)},
+			expErr:   &pq.Error{Code: "40P01"},
+			expCalls: 2,
+		},
+		{
+			desc: "join outer transaction",
+			mockFunc: func() {
+				s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+			},
+			fn: func(calls *int) func(Task) error {
+				return func(t Task) error {
+					*calls++
+					err := t.WithTx(mockCTX, func(inner Task) error {
+						_, err := inner.Create(mockCTX, &models.CreateTaskParams{Name: name})
+						return err
+					})
+					if err != nil {
+						return err
+					}
+					return errMock
+				}
+			},
+			expErr:   errMock,
+			expCalls: 1,
+		},
+		{
+			desc:     "join outer transaction as isolated as requested",
+			mockFunc: func() {},
+			fn: func(calls *int) func(Task) error {
+				return func(t Task) error {
+					*calls++
+					return t.WithTx(mockCTX, func(inner Task) error {
+						return nil
+					}, WithIsolation(sql.LevelRepeatableRead))
+				}
+			},
+			opts:     []TxOptionFunc{WithIsolation(sql.LevelSerializable)},
+			expCalls: 1,
+		},
+		{
+			desc:     "reject outer transaction less isolated than requested",
+			mockFunc: func() {},
+			fn: func(calls *int) func(Task) error {
+				return func(t Task) error {
+					*calls++
+					return t.WithTx(mockCTX, func(inner Task) error {
+						return nil
+					}, WithIsolation(sql.LevelSerializable))
+				}
+			},
+			expErr:   ErrTxIsolation,
+			expCalls: 1,
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		test.mockFunc()
+		calls := 0
+		err := s.taskStore.WithTx(mockCTX, test.fn(&calls), test.opts...)
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+		}
+		s.Require().Equal(test.expCalls, calls, test.desc)
+
+		tasks, _, err := s.taskStore.List(mockCTX)
+		s.Require().NoError(err, test.desc)
+		s.Require().Len(tasks, test.expTaskNum, test.desc)
+
+		s.TearDownTest()
+	}
+}
+
+func (s *taskSuite) TestQueryTimeout() {
+	store := New(s.DB, postgres.WithQueryTimeout(50*time.Millisecond), postgres.WithSlowQueryThreshold(10*time.Millisecond)).(*impl)
+
+	_, err := store.q.Exec(mockCTX, "SELECT pg_sleep(0.01)")
+	s.Require().NoError(err)
+
+	_, err = store.q.Exec(mockCTX, "SELECT pg_sleep(1)")
+	s.Require().EqualError(postgres.TranslateError(err), postgres.ErrQueryCanceled.Error())
+
+	ctx, cancel := context.WithCancel(mockCTX)
+	cancel()
+	_, err = store.Get(ctx, mockUUID.String())
+	s.Require().EqualError(postgres.TranslateError(err), postgres.ErrQueryCanceled.Error())
+}
+
+func (s *taskSuite) TestSubtasks() {
+	child := uuid.New()
+	grandchild := uuid.New()
+	archived := uuid.New()
+	deletedAlone := uuid.New()
+	s.createTask()
+	s.createTask(createWithID(child), createWithParent(mockUUID), createWithStatus(models.TaskStatusDone))
+	s.createTask(createWithID(grandchild), createWithParent(child))
+	s.createTask(createWithID(archived), createWithParent(mockUUID), createWithStatus(models.TaskStatusArchived))
+	s.createTask(createWithID(deletedAlone), createWithParent(mockUUID))
+	s.createTask(createWithID(uuid.New()), createWithParent(mockUUID))
+	_, err := s.DB.Exec("UPDATE tasks SET deleted_at=$1 WHERE id=$2", mockNow.Add(-time.Hour), deletedAlone)
+	s.Require().NoError(err)
+
+	// progress
+	parent, err := s.taskStore.Get(mockCTX, mockUUID.String())
+	s.Require().NoError(err)
+	s.Require().Equal(2, parent.SubtaskCount)
+	s.Require().Equal(1, parent.SubtaskDoneCount)
+	s.Require().Equal(&models.TaskProgress{Done: 1, Total: 2, Percent: 50}, parent.Parse().Progress)
+
+	subtasks, _, err := s.taskStore.List(mockCTX, WithParent(mockUUID))
+	s.Require().NoError(err)
+	s.Require().Len(subtasks, 3)
+
+	// cascading deletion
+	s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Twice()
+	s.Require().NoError(s.taskStore.Delete(mockCTX, mockUUID.String()))
+	tasks, _, err := s.taskStore.List(mockCTX)
+	s.Require().NoError(err)
+	s.Require().Empty(tasks)
+
+	// cascading restoration skips the subtask deleted on its own
+	_, err = s.taskStore.Restore(mockCTX, mockUUID.String())
+	s.Require().NoError(err)
+	tasks, _, err = s.taskStore.List(mockCTX)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 5)
+	alone, err := s.taskStore.Get(mockCTX, deletedAlone.String())
+	s.Require().NoError(err)
+	s.Require().True(alone.DeletedAt.Valid)
+}
+
+func (s *taskSuite) TestDependencies() {
+	a := s.createTask(createWithID(uuid.New()), createWithName("a"), createWithCreatedAt(mockNow)).ID.String()
+	b := s.createTask(createWithID(uuid.New()), createWithName("b"), createWithCreatedAt(mockNow.Add(time.Minute))).ID.String()
+	c := s.createTask(createWithID(uuid.New()), createWithName("c"), createWithCreatedAt(mockNow.Add(-time.Minute))).ID.String()
+
+	// c is blocked by a, which is blocked by b
+	s.Require().NoError(s.taskStore.AddBlocker(mockCTX, a, b))
+	s.Require().NoError(s.taskStore.AddBlocker(mockCTX, a, b))
+	s.Require().NoError(s.taskStore.AddBlocker(mockCTX, c, a))
+	s.Require().EqualError(s.taskStore.AddBlocker(mockCTX, a, a), ErrDependencyCycle.Error())
+	s.Require().EqualError(s.taskStore.AddBlocker(mockCTX, b, c), ErrDependencyCycle.Error())
+	s.Require().EqualError(s.taskStore.AddBlocker(mockCTX, a, uuid.New().String()), ErrBlockerNotFound.Error())
+
+	blockers, err := s.taskStore.ListBlockers(mockCTX, a)
+	s.Require().NoError(err)
+	s.Require().Len(blockers, 1)
+	s.Require().Equal("b", blockers[0].Name)
+
+	tasks, _, err := s.taskStore.List(mockCTX, WithSort("dependency"))
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"b", "a", "c"}, []string{tasks[0].Name, tasks[1].Name, tasks[2].Name})
+
+	// a can't be done until b is
+	done := models.TaskStatusDone
+	s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Times(3)
+	_, err = s.taskStore.Patch(mockCTX, a, &models.PatchTaskParams{Status: &done})
+	s.Require().EqualError(err, ErrTaskBlocked.Error())
+	_, err = s.taskStore.Patch(mockCTX, b, &models.PatchTaskParams{Status: &done})
+	s.Require().NoError(err)
+	task, err := s.taskStore.Patch(mockCTX, a, &models.PatchTaskParams{Status: &done})
+	s.Require().NoError(err)
+	s.Require().False(task.Parse().Blocked)
+
+	s.Require().NoError(s.taskStore.RemoveBlocker(mockCTX, c, a))
+	s.Require().EqualError(s.taskStore.RemoveBlocker(mockCTX, c, a), ErrDependencyNotFound.Error())
+}
+
+func (s *taskSuite) TestLists() {
+	work := uuid.New()
+	archived := uuid.New()
+	_, err := s.DB.Exec("INSERT INTO lists (id, name) VALUES ($1, 'work')", work)
+	s.Require().NoError(err)
+	_, err = s.DB.Exec("INSERT INTO lists (id, name, archived_at) VALUES ($1, 'archived', $2)", archived, mockNow)
+	s.Require().NoError(err)
+	s.createTask()
+
+	s.mockFuncs.On("timeNow").Return(mockNow).Times(3)
+	task, err := s.taskStore.Create(mockCTX, &models.CreateTaskParams{Name: "in list", ListID: &work})
+	s.Require().NoError(err)
+	s.Require().Equal(work, *task.Parse().ListID)
+	_, err = s.taskStore.Create(mockCTX, &models.CreateTaskParams{Name: "in archived list", ListID: &archived})
+	s.Require().EqualError(err, ErrListArchived.Error())
+	missing := uuid.New()
+	_, err = s.taskStore.Create(mockCTX, &models.CreateTaskParams{Name: "in missing list", ListID: &missing})
+	s.Require().EqualError(err, ErrListNotFound.Error())
+
+	tasks, _, err := s.taskStore.List(mockCTX, WithList(work))
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 1)
+	s.Require().Equal("in list", tasks[0].Name)
+
+	// move the other task into the list and out of it
+	s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Twice()
+	moved, err := s.taskStore.Patch(mockCTX, mockUUID.String(), &models.PatchTaskParams{ListID: &work})
+	s.Require().NoError(err)
+	s.Require().Equal(uuid.NullUUID{UUID: work, Valid: true}, moved.ListID)
+	_, err = s.taskStore.Patch(mockCTX, mockUUID.String(), &models.PatchTaskParams{ListID: &archived})
+	s.Require().EqualError(err, ErrListArchived.Error())
+	tasks, _, err = s.taskStore.List(mockCTX, WithList(work))
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 2)
+
+	moved, err = s.taskStore.Patch(mockCTX, mockUUID.String(), &models.PatchTaskParams{ClearListID: true})
+	s.Require().NoError(err)
+	s.Require().False(moved.ListID.Valid)
+	s.Require().Nil(moved.Parse().ListID)
+}
+
+func (s *taskSuite) TestMove() {
+	a := uuid.New()
+	b := uuid.New()
+	c := uuid.New()
+	deleted := uuid.New()
+	missing := uuid.New()
+
+	tests := []struct {
+		desc     string
+		params   *models.MoveTaskParams
+		id       uuid.UUID
+		expNames []string
+		expErr   error
+	}{
+		{
+			desc:     "move after task",
+			id:       a,
+			params:   &models.MoveTaskParams{AfterID: &b},
+			expNames: []string{"b", "a", "c"},
+		},
+		{
+			desc:     "move after last task",
+			id:       a,
+			params:   &models.MoveTaskParams{AfterID: &c},
+			expNames: []string{"b", "c", "a"},
+		},
+		{
+			desc:     "move before first task",
+			id:       c,
+			params:   &models.MoveTaskParams{BeforeID: &a},
+			expNames: []string{"c", "a", "b"},
+		},
+		{
+			desc:     "move between tasks",
+			id:       c,
+			params:   &models.MoveTaskParams{AfterID: &a, BeforeID: &b},
+			expNames: []string{"a", "c", "b"},
+		},
+		{
+			desc:   "move between reversed tasks",
+			id:     c,
+			params: &models.MoveTaskParams{AfterID: &b, BeforeID: &a},
+			expErr: ErrInvalidMove,
+		},
+		{
+			desc:   "move after itself",
+			id:     a,
+			params: &models.MoveTaskParams{AfterID: &a},
+			expErr: ErrInvalidMove,
+		},
+		{
+			desc:   "move without neighbor",
+			id:     a,
+			params: &models.MoveTaskParams{},
+			expErr: ErrInvalidMove,
+		},
+		{
+			desc:   "move after deleted task",
+			id:     a,
+			params: &models.MoveTaskParams{AfterID: &deleted},
+			expErr: ErrNeighborNotFound,
+		},
+		{
+			desc:   "move before missing task",
+			id:     a,
+			params: &models.MoveTaskParams{BeforeID: &missing},
+			expErr: ErrNeighborNotFound,
+		},
+		{
+			desc:   "move deleted task",
+			id:     deleted,
+			params: &models.MoveTaskParams{AfterID: &a},
+			expErr: ErrTaskDeleted,
+		},
+		{
+			desc:   "move missing task",
+			id:     missing,
+			params: &models.MoveTaskParams{AfterID: &a},
+			expErr: ErrTaskNotFound,
+		},
+	}
+
+	s.TearDownTest()
+	for _, test := range tests {
+		s.SetupTest()
+
+		s.createTask(createWithID(a), createWithName("a"))
+		s.createTask(createWithID(b), createWithName("b"))
+		s.createTask(createWithID(c), createWithName("c"))
+		s.createTask(createWithID(deleted), createWithName("deleted"))
+		s.deleteTask(deleted)
+
+		if test.expErr == nil {
+			s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+		}
+		task, err := s.taskStore.Move(mockCTX, test.id.String(), test.params)
+		if test.expErr != nil {
+			s.Require().EqualError(err, test.expErr.Error(), test.desc)
+		} else {
+			s.Require().NoError(err, test.desc)
+			s.Require().Equal(2, task.Version, test.desc)
+
+			tasks, _, err := s.taskStore.List(mockCTX)
+			s.Require().NoError(err, test.desc)
+			names := make([]string, len(tasks))
+			for i, t := range tasks {
+				names[i] = t.Name
+			}
+			s.Require().Equal(test.expNames, names, test.desc)
+		}
+
+		s.TearDownTest()
+	}
+}
+
+func (s *taskSuite) TestConcurrentPositions() {
+	n := 10
+	s.mockFuncs.On("timeNow").Return(mockNow).Times(n + 1)
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.taskStore.Create(mockCTX, &models.CreateTaskParams{Name: fmt.Sprintf("task-%d", i)})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		s.Require().NoError(err)
+	}
+
+	tasks, _, err := s.taskStore.List(mockCTX)
+	s.Require().NoError(err)
+	s.Require().Len(tasks, n)
+	positions := map[string]bool{}
+	for _, t := range tasks {
+		positions[t.Position] = true
+	}
+	s.Require().Len(positions, n)
+
+	// tied positions are rejected, so there is always room between two tasks
+	_, err = s.DB.Exec("UPDATE tasks SET position=$1 WHERE id=$2", tasks[1].Position, tasks[2].ID)
+	s.Require().Error(err)
+	_, err = s.taskStore.Move(mockCTX, tasks[0].ID.String(), &models.MoveTaskParams{AfterID: &tasks[1].ID, BeforeID: &tasks[2].ID})
+	s.Require().NoError(err)
+}
+
+func (s *taskSuite) TestSpreadPositions() {
+	a := s.createTask(createWithID(uuid.New()), createWithName("a"))
+	b := s.createTask(createWithID(uuid.New()), createWithName("b"))
+	c := s.createTask(createWithID(uuid.New()), createWithName("c"))
+	// b is right after a, so a position between them is too long
+	_, err := s.DB.Exec("UPDATE tasks SET position=$1 WHERE id=$2", "i", a.ID)
+	s.Require().NoError(err)
+	_, err = s.DB.Exec("UPDATE tasks SET position=$1 WHERE id=$2", "i"+strings.Repeat("0", maxPositionLength-2)+"1", b.ID)
+	s.Require().NoError(err)
+
+	s.mockFuncs.On("timeNow").Return(mockNow.Add(7 * time.Minute)).Once()
+	_, err = s.taskStore.Move(mockCTX, c.ID.String(), &models.MoveTaskParams{AfterID: &a.ID, BeforeID: &b.ID})
+	s.Require().NoError(err)
+
+	tasks, _, err := s.taskStore.List(mockCTX)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"a", "c", "b"}, []string{tasks[0].Name, tasks[1].Name, tasks[2].Name})
+	for _, t := range tasks {
+		s.Require().LessOrEqual(len(t.Position), lexorank.Width, t.Name)
+	}
+	// spreading positions doesn't update tasks
+	s.Require().Equal(1, tasks[0].Version)
+	s.Require().Equal(1, tasks[2].Version)
+
+	// positions grown long are spread once tasks are purged
+	d := s.createTask(createWithID(uuid.New()), createWithName("d"))
+	s.deleteTask(d.ID)
+	_, err = s.DB.Exec("UPDATE tasks SET position=$1 WHERE id=$2", tasks[0].Position+strings.Repeat("0", lexorank.Width)+"1", a.ID)
+	s.Require().NoError(err)
+	n, err := s.taskStore.PurgeDeleted(mockCTX, mockNow.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().Equal(1, n)
+	tasks, _, err = s.taskStore.List(mockCTX)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"a", "c", "b"}, []string{tasks[0].Name, tasks[1].Name, tasks[2].Name})
+	for _, t := range tasks {
+		s.Require().LessOrEqual(len(t.Position), lexorank.Width, t.Name)
+	}
+}
+
+func (s *taskSuite) TestRecur() {
+	weekly := s.createTask(createWithID(uuid.New()), createWithName("weekly"), createWithDueAt(mockNow.Add(-time.Hour)))
+	once := s.createTask(createWithID(uuid.New()), createWithName("once"), createWithDueAt(mockNow.Add(-time.Hour)))
+	doneEarly := s.createTask(createWithID(uuid.New()), createWithName("done early"), createWithDueAt(mockNow.Add(time.Hour)),
+		createWithStatus(models.TaskStatusDone))
+	notDue := s.createTask(createWithID(uuid.New()), createWithName("not due"), createWithDueAt(mockNow.Add(time.Hour)))
+	archived := s.createTask(createWithID(uuid.New()), createWithName("archived"), createWithDueAt(mockNow.Add(-time.Hour)),
+		createWithStatus(models.TaskStatusArchived))
+	for id, rule := range map[uuid.UUID]string{
+		weekly.ID:    "FREQ=WEEKLY",
+		once.ID:      "FREQ=DAILY;COUNT=1",
+		doneEarly.ID: "FREQ=DAILY",
+		notDue.ID:    "FREQ=DAILY",
+		archived.ID:  "FREQ=DAILY",
+	} {
+		_, err := s.DB.Exec("UPDATE tasks SET rrule=$1 WHERE id=$2", rule, id)
+		s.Require().NoError(err)
+	}
+	s.createLabels("chore")
+	s.attachLabels(weekly.ID, "chore")
+
+	n, err := s.taskStore.Recur(mockCTX, mockNow, 10)
+	s.Require().NoError(err)
+	s.Require().Equal(3, n)
+	// NOTE: a task is handled once, so recurring again makes nothing
+	n, err = s.taskStore.Recur(mockCTX, mockNow, 10)
+	s.Require().NoError(err)
+	s.Require().Zero(n)
+
+	tasks, _, err := s.taskStore.List(mockCTX, WithSort("created_at"), WithLimit(100))
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 7)
+	series := map[uuid.UUID][]*models.Task{}
+	for _, t := range tasks {
+		if t.SeriesID.Valid {
+			series[t.SeriesID.UUID] = append(series[t.SeriesID.UUID], t)
+		}
+	}
+	s.Require().Len(series, 3)
+	s.Require().Len(series[once.ID], 1)
+	s.Require().Len(series[doneEarly.ID], 2)
+
+	s.Require().Len(series[weekly.ID], 2)
+	next := series[weekly.ID][1]
+	s.Require().Equal("weekly", next.Name)
+	s.Require().Equal(models.TaskStatusTodo, next.Status)
+	s.Require().Equal(2, next.Occurrence)
+	s.Require().Equal("FREQ=WEEKLY", next.RRule.String)
+	s.Require().True(mockNow.Add(-time.Hour).AddDate(0, 0, 7).Equal(next.DueAt.Time))
+	s.Require().Equal(pq.StringArray{"chore"}, next.Labels)
+
+	// a rule needs the due date
+	_, err = s.taskStore.Create(mockCTX, &models.CreateTaskParams{Name: "without due date", RRule: "FREQ=DAILY"})
+	s.Require().EqualError(err, ErrRRuleWithoutDueAt.Error())
+	_, err = s.taskStore.Create(mockCTX, &models.CreateTaskParams{Name: "hourly", RRule: "FREQ=HOURLY", DueAt: &mockNow})
+	s.Require().EqualError(err, ErrInvalidRRule.Error())
+	s.mockFuncs.On("timeNow").Return(mockNow).Once()
+	_, err = s.taskStore.Patch(mockCTX, notDue.ID.String(), &models.PatchTaskParams{ClearDueAt: true})
+	s.Require().EqualError(err, ErrRRuleWithoutDueAt.Error())
+}
+
+func (s *taskSuite) TestRecurOverdue() {
+	dueAt := mockNow.AddDate(0, 0, -30)
+	daily := s.createTask(createWithID(uuid.New()), createWithName("daily"), createWithDueAt(dueAt))
+	limited := s.createTask(createWithID(uuid.New()), createWithName("limited"), createWithDueAt(dueAt))
+	for id, rule := range map[uuid.UUID]string{
+		daily.ID:   "FREQ=DAILY",
+		limited.ID: "FREQ=DAILY;COUNT=5",
+	} {
+		_, err := s.DB.Exec("UPDATE tasks SET rrule=$1 WHERE id=$2", rule, id)
+		s.Require().NoError(err)
+	}
+
+	n, err := s.taskStore.Recur(mockCTX, mockNow, 10)
+	s.Require().NoError(err)
+	s.Require().Equal(2, n)
+
+	// NOTE: the missed occurrences are skipped rather than made one by one
+	tasks, _, err := s.taskStore.List(mockCTX, WithSort("created_at"), WithLimit(100))
+	s.Require().NoError(err)
+	s.Require().Len(tasks, 3)
+	var next *models.Task
+	for _, t := range tasks {
+		if t.ID != daily.ID && t.ID != limited.ID {
+			next = t
+		}
+	}
+	s.Require().NotNil(next)
+	s.Require().Equal(daily.ID, next.SeriesID.UUID)
+	s.Require().Equal(32, next.Occurrence)
+	s.Require().True(dueAt.AddDate(0, 0, 31).Equal(next.DueAt.Time))
+
+	n, err = s.taskStore.Recur(mockCTX, mockNow, 10)
+	s.Require().NoError(err)
+	s.Require().Zero(n)
+}
+
+func (s *taskSuite) TestOutbox() {
+	type event struct {
+		Event  string    `db:"event"`
+		TaskID uuid.UUID `db:"task_id"`
+	}
+	events := func() []event {
+		es := []event{}
+		s.Require().NoError(s.DB.Select(&es, "SELECT event, task_id FROM outbox ORDER BY pk"))
+		return es
+	}
+
+	s.mockFuncs.On("timeNow").Return(mockNow).Times(6)
+	task, err := s.taskStore.Create(mockCTX, &models.CreateTaskParams{Name: "mock-outbox-name"})
+	s.Require().NoError(err)
+	subtask := s.createTask(createWithID(uuid.New()), createWithParent(task.ID))
+	name := "mock-outbox-name-2"
+	_, err = s.taskStore.Patch(mockCTX, task.ID.String(), &models.PatchTaskParams{Name: &name})
+	s.Require().NoError(err)
+	s.Require().NoError(s.taskStore.Delete(mockCTX, task.ID.String()))
+	s.Require().Equal([]event{
+		{Event: models.TaskEventCreated, TaskID: task.ID},
+		{Event: models.TaskEventUpdated, TaskID: task.ID},
+		{Event: models.TaskEventDeleted, TaskID: task.ID},
+		{Event: models.TaskEventDeleted, TaskID: subtask.ID},
+	}, events())
+
+	payload := types.JSONText{}
+	s.Require().NoError(s.DB.Get(&payload, "SELECT payload FROM outbox WHERE event=$1", models.TaskEventUpdated))
+	updated := models.DisplayTask{}
+	s.Require().NoError(payload.Unmarshal(&updated))
+	s.Require().Equal(name, updated.Name)
+
+	// failed mutations write no event
+	_, err = s.taskStore.Patch(mockCTX, task.ID.String(), &models.PatchTaskParams{Name: &name})
+	s.Require().EqualError(err, ErrTaskDeleted.Error())
+	results, err := s.taskStore.Batch(mockCTX, []*models.BatchTaskOperation{
+		{Op: models.BatchOpCreate, Name: &name},
+		{Op: models.BatchOpDelete, ID: mockUUID2.String()},
+	}, true)
+	s.Require().NoError(err)
+	s.Require().EqualError(results[0].Err, ErrBatchRolledBack.Error())
+	s.Require().Len(events(), 4)
+}
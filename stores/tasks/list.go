@@ -0,0 +1,25 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// checkList verifies that tasks can be put in the list, which must exist and mustn't be archived
+func (im *impl) checkList(ctx context.Context, listID uuid.UUID) error {
+	archivedAt := pq.NullTime{}
+	// NOTE: the row is locked so that the list can't be archived or deleted until the task is written
+	if err := im.q.Get(ctx, &archivedAt, "SELECT archived_at FROM lists WHERE id=$1 FOR SHARE", listID); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrListNotFound
+		}
+		return err
+	}
+	if archivedAt.Valid {
+		return ErrListArchived
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+// emit writes the events of the tasks to the outbox. It must run in the transaction of the mutation,
+// so that the events are published if and only if the mutation is committed.
+// NOTE: created_at is the start time of the transaction (CURRENT_TIMESTAMP), not the commit time
+func (im *impl) emit(ctx context.Context, event string, tasks ...*models.Task) error {
+	s := "INSERT INTO outbox (id, event, task_id, payload) VALUES ($1, $2, $3, $4)"
+	for _, task := range tasks {
+		payload, err := json.Marshal(task.Parse())
+		if err != nil {
+			return err
+		}
+		if _, err := im.q.Exec(ctx, s, uuid.New(), event, task.ID, types.JSONText(payload)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/base/lexorank"
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+const (
+	// positionLockKey is the key of the advisory lock taken while placing tasks
+	positionLockKey = 0x7461736b73 // "tasks"
+	// maxPositionLength is how long positions grow before they're spread again, far below the size limit of the index
+	maxPositionLength = 32
+)
+
+func (im *impl) Move(ctx context.Context, id string, params *models.MoveTaskParams, opts ...MutateTaskOptionFunc) (*models.Task, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	if params.AfterID == nil && params.BeforeID == nil {
+		return nil, ErrInvalidMove
+	}
+
+	var task *models.Task
+	err = im.WithTx(ctx, func(t Task) error {
+		txStore := t.(*impl)
+		// NOTE: tasks moved into the same gap at the same time would get the same position otherwise
+		if err := txStore.lockPositions(ctx); err != nil {
+			return err
+		}
+		position, err := txStore.placeBetween(ctx, func() (string, string, error) {
+			return txStore.neighborPositions(ctx, parsedID, params)
+		})
+		if err == lexorank.ErrInvalidRange {
+			return ErrInvalidMove
+		}
+		if err != nil {
+			return err
+		}
+
+		m := &mutation{deleted: new(bool)}
+		m.set("position", position)
+		task, err = txStore.update(ctx, parsedID, m, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// neighborPositions returns the positions the task is moved between. The neighbor which isn't given is
+// the task next to the given one, an empty position means the start or the end.
+func (im *impl) neighborPositions(ctx context.Context, id uuid.UUID, params *models.MoveTaskParams) (string, string, error) {
+	lower, upper := "", ""
+	var err error
+	if params.AfterID != nil {
+		if lower, err = im.neighborPosition(ctx, id, *params.AfterID); err != nil {
+			return "", "", err
+		}
+	}
+	if params.BeforeID != nil {
+		if upper, err = im.neighborPosition(ctx, id, *params.BeforeID); err != nil {
+			return "", "", err
+		}
+	}
+
+	if params.BeforeID == nil {
+		s := "SELECT COALESCE(MIN(position), '') FROM tasks WHERE position > $1 AND id<>$2"
+		if err := im.q.Get(ctx, &upper, s, lower, id); err != nil {
+			return "", "", err
+		}
+	}
+	if params.AfterID == nil {
+		s := "SELECT COALESCE(MAX(position), '') FROM tasks WHERE position < $1 AND id<>$2"
+		if err := im.q.Get(ctx, &lower, s, upper, id); err != nil {
+			return "", "", err
+		}
+	}
+	return lower, upper, nil
+}
+
+// neighborPosition returns the position of the neighbor, which must be another task and mustn't be deleted
+func (im *impl) neighborPosition(ctx context.Context, id, neighborID uuid.UUID) (string, error) {
+	if neighborID == id {
+		return "", ErrInvalidMove
+	}
+	neighbor, err := im.Get(ctx, neighborID.String())
+	if err == ErrTaskNotFound || (err == nil && neighbor.DeletedAt.Valid) {
+		return "", ErrNeighborNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return neighbor.Position, nil
+}
+
+// appendPosition returns a position after every task. It locks the positions so that the caller can
+// place a task at it, so it must run in a transaction.
+func (im *impl) appendPosition(ctx context.Context) (string, error) {
+	if err := im.lockPositions(ctx); err != nil {
+		return "", err
+	}
+	return im.placeBetween(ctx, func() (string, string, error) {
+		last := ""
+		err := im.q.Get(ctx, &last, "SELECT COALESCE(MAX(position), '') FROM tasks")
+		return last, "", err
+	})
+}
+
+// placeBetween returns a position between the ones read by neighbors, the positions are spread first
+// if it'd be longer than maxPositionLength. The positions must be locked.
+func (im *impl) placeBetween(ctx context.Context, neighbors func() (string, string, error)) (string, error) {
+	for spread := false; ; spread = true {
+		lower, upper, err := neighbors()
+		if err != nil {
+			return "", err
+		}
+		position, err := lexorank.Between(lower, upper)
+		if err != nil || len(position) <= maxPositionLength || spread {
+			return position, err
+		}
+		if err := im.spreadPositions(ctx); err != nil {
+			return "", err
+		}
+	}
+}
+
+// spreadPositions rewrites the positions of every task, deleted ones included, evenly at the same length
+// in the same order. The positions must be locked. Tasks aren't updated by it, their versions are kept.
+func (im *impl) spreadPositions(ctx context.Context) error {
+	n := 0
+	if err := im.q.Get(ctx, &n, "SELECT COUNT(*) FROM tasks"); err != nil {
+		return err
+	}
+	positions, err := lexorank.Spread(n)
+	if err != nil {
+		return err
+	}
+
+	// NOTE: positions are unique, so the old ones are moved out of the way of the new ones first.
+	// "~" goes after every digit in byte order.
+	if _, err := im.q.Exec(ctx, "UPDATE tasks SET position='~' || position"); err != nil {
+		return err
+	}
+	s := "UPDATE tasks SET position=p.position FROM (SELECT pk, row_number() OVER (ORDER BY position) AS n FROM tasks) r\n" +
+		"JOIN unnest($1::TEXT[]) WITH ORDINALITY p(position, n) ON p.n=r.n WHERE tasks.pk=r.pk"
+	if _, err := im.q.Exec(ctx, s, pq.Array(positions)); err != nil {
+		return err
+	}
+	zerolog.Ctx(ctx).Info().Int("count", n).Msg("positions spread")
+	return nil
+}
+
+// compactPositions spreads the positions if some are longer than the ones appended
+func (im *impl) compactPositions(ctx context.Context) error {
+	return im.WithTx(ctx, func(t Task) error {
+		txStore := t.(*impl)
+		if err := txStore.lockPositions(ctx); err != nil {
+			return err
+		}
+		long := false
+		s := "SELECT EXISTS (SELECT 1 FROM tasks WHERE LENGTH(position) > $1)"
+		if err := txStore.q.Get(ctx, &long, s, lexorank.Width); err != nil {
+			return err
+		}
+		if !long {
+			return nil
+		}
+		return txStore.spreadPositions(ctx)
+	})
+}
+
+// lockPositions keeps other transactions from placing tasks until the transaction ends.
+// Positions are read and written in the same transaction, so concurrent ones would pick the same position.
+func (im *impl) lockPositions(ctx context.Context) error {
+	_, err := im.q.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", positionLockKey)
+	return err
+}
@@ -0,0 +1,131 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/base/rrule"
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+const (
+	rruleDueAtConstraint = "tasks_rrule_due_at_check"
+
+	// recurringConds are where the tasks to recur are, which are neither archived, deleted nor handled yet
+	recurringConds = "rrule IS NOT NULL AND recurred_at IS NULL AND deleted_at IS NULL AND status<>'archived'"
+)
+
+// nullRRule validates the rule and converts it to its column value, an empty rule means no recurrence
+func nullRRule(s string) (sql.NullString, error) {
+	if s == "" {
+		return sql.NullString{}, nil
+	}
+	if _, err := rrule.Parse(s); err != nil {
+		return sql.NullString{}, ErrInvalidRRule
+	}
+	return sql.NullString{String: s, Valid: true}, nil
+}
+
+func isRRuleDueAtViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Constraint == rruleDueAtConstraint
+}
+
+func (im *impl) Recur(ctx context.Context, now time.Time, limit int) (int, error) {
+	n := 0
+	err := im.WithTx(ctx, func(t Task) error {
+		txStore := t.(*impl)
+		// NOTE: tasks locked by another replica are skipped, so every task is handled by one of them
+		s := "SELECT " + taskColumns + " FROM tasks WHERE " + recurringConds + " AND (status='done' OR due_at <= $1)\n" +
+			"ORDER BY due_at, pk LIMIT $2 FOR UPDATE SKIP LOCKED"
+		due := []*models.Task{}
+		if err := txStore.q.Select(ctx, &due, s, now, limit); err != nil {
+			return err
+		}
+
+		for _, task := range due {
+			if err := txStore.recur(ctx, task, now); err != nil {
+				return err
+			}
+		}
+		n = len(due)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// recur makes the next occurrence of the task unless the series ends, and marks the task handled
+func (im *impl) recur(ctx context.Context, task *models.Task, now time.Time) error {
+	seriesID := task.ID
+	if task.SeriesID.Valid {
+		seriesID = task.SeriesID.UUID
+	}
+
+	rule, err := rrule.Parse(task.RRule.String)
+	if err != nil {
+		// NOTE: rules are validated when they're written, so the series is ended rather than retried forever
+		zerolog.Ctx(ctx).Warn().Err(err).Str("task_id", task.ID.String()).Msg("rrule.Parse failed")
+	}
+	// NOTE: occurrences missed while the task was overdue are skipped, the next one is the first after now.
+	// The skipped ones still count towards COUNT of the rule.
+	next, occurrence, ok := task.DueAt.Time, task.Occurrence, rule != nil
+	for ok {
+		if rule.Count > 0 && occurrence >= rule.Count {
+			ok = false
+			break
+		}
+		next, ok = rule.Next(next)
+		occurrence++
+		if next.After(now) {
+			break
+		}
+	}
+
+	if ok {
+		position, err := im.appendPosition(ctx)
+		if err != nil {
+			return err
+		}
+
+		// NOTE: the next occurrence is a copy of the task which isn't done, it leaves an archived list.
+		// The unique index of series_id and occurrence keeps an occurrence from being made twice.
+		s := "INSERT INTO tasks (id, parent_id, list_id, name, description, status, priority, due_at, created_at, updated_at,\n" +
+			"version, position, rrule, series_id, occurrence)\n" +
+			"SELECT $1, parent_id, (SELECT l.id FROM lists l WHERE l.id=tasks.list_id AND l.archived_at IS NULL),\n" +
+			"name, description, 'todo', priority, $2, $3, $3, 1, $4, rrule, $5, $6 FROM tasks WHERE id=$7\n" +
+			"ON CONFLICT (series_id, occurrence) DO NOTHING"
+		id := uuid.New()
+		res, err := im.q.Exec(ctx, s, id, next, now, position, seriesID, occurrence, task.ID)
+		if err != nil {
+			return err
+		}
+		if made, err := res.RowsAffected(); err != nil {
+			return err
+		} else if made > 0 {
+			s := "INSERT INTO task_labels (task_pk, label_pk)\n" +
+				"SELECT n.pk, tl.label_pk FROM tasks n, tasks o JOIN task_labels tl ON tl.task_pk=o.pk WHERE n.id=$1 AND o.id=$2"
+			if _, err := im.q.Exec(ctx, s, id, task.ID); err != nil {
+				return err
+			}
+			created, err := im.Get(ctx, id.String())
+			if err != nil {
+				return err
+			}
+			if err := im.emit(ctx, models.TaskEventCreated, created); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = im.q.Exec(ctx, "UPDATE tasks SET series_id=$1, recurred_at=$2 WHERE id=$3", seriesID, now, task.ID)
+	return err
+}
@@ -0,0 +1,35 @@
+package tasks
+
+import "github.com/chihkaiyu/task-todo-api/models"
+
+// statusTransitions lists the statuses a task is allowed to move to from each status,
+// staying at the same status is always allowed
+var statusTransitions = map[models.TaskStatus][]models.TaskStatus{
+	models.TaskStatusTodo:       {models.TaskStatusInProgress, models.TaskStatusDone, models.TaskStatusArchived},
+	models.TaskStatusInProgress: {models.TaskStatusTodo, models.TaskStatusDone, models.TaskStatusArchived},
+	models.TaskStatusDone:       {models.TaskStatusTodo, models.TaskStatusInProgress, models.TaskStatusArchived},
+	models.TaskStatusArchived:   {models.TaskStatusTodo},
+}
+
+func canTransit(from, to models.TaskStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, s := range statusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitSources returns the statuses which are allowed to move to the status
+func transitSources(to models.TaskStatus) []string {
+	sources := []string{}
+	for from := range statusTransitions {
+		if canTransit(from, to) {
+			sources = append(sources, string(from))
+		}
+	}
+	return sources
+}
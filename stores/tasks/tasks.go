@@ -2,17 +2,69 @@ package tasks
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/chihkaiyu/task-todo-api/models"
 )
 
 var (
-	ErrTaskNotFound = models.NotFoundErr{Code: "TASK_NOT_FOUND"}
-	ErrInvalidID    = models.BadRequestErr{Code: "INVALID_ID"}
+	ErrTaskNotFound  = models.NotFoundErr{Code: "TASK_NOT_FOUND"}
+	ErrInvalidID     = models.BadRequestErr{Code: "INVALID_ID"}
+	ErrInvalidCursor = models.BadRequestErr{Code: "INVALID_CURSOR"}
+	ErrInvalidSort   = models.BadRequestErr{Code: "INVALID_SORT"}
+
+	ErrVersionMismatch         = models.PreconditionFailedErr{Code: "VERSION_MISMATCH"}
+	ErrInvalidStatusTransition = models.ConflictErr{Code: "INVALID_STATUS_TRANSITION"}
+	ErrConcurrentModification  = models.ConflictErr{Code: "CONCURRENT_MODIFICATION"}
+	ErrTaskNotDeleted          = models.ConflictErr{Code: "TASK_NOT_DELETED"}
+	ErrTaskDeleted             = models.GoneErr{Code: "TASK_DELETED"}
+
+	ErrUnknownLabel   = models.BadRequestErr{Code: "UNKNOWN_LABEL"}
+	ErrParentNotFound = models.BadRequestErr{Code: "PARENT_NOT_FOUND"}
+	ErrTaskCycle      = models.ConflictErr{Code: "TASK_CYCLE"}
+
+	ErrListNotFound = models.BadRequestErr{Code: "LIST_NOT_FOUND"}
+	ErrListArchived = models.ConflictErr{Code: "LIST_ARCHIVED"}
+
+	ErrNeighborNotFound = models.BadRequestErr{Code: "NEIGHBOR_NOT_FOUND"}
+	ErrInvalidMove      = models.BadRequestErr{Code: "INVALID_MOVE"}
+
+	ErrInvalidRRule      = models.BadRequestErr{Code: "INVALID_RRULE"}
+	ErrRRuleWithoutDueAt = models.BadRequestErr{Code: "RRULE_WITHOUT_DUE_AT"}
+
+	ErrBlockerNotFound    = models.BadRequestErr{Code: "BLOCKER_NOT_FOUND"}
+	ErrDependencyNotFound = models.NotFoundErr{Code: "DEPENDENCY_NOT_FOUND"}
+	ErrDependencyCycle    = models.ConflictErr{Code: "DEPENDENCY_CYCLE"}
+	ErrTaskBlocked        = models.ConflictErr{Code: "TASK_BLOCKED"}
+
+	ErrInvalidBatchOperation = models.BadRequestErr{Code: "INVALID_BATCH_OPERATION"}
+	ErrBatchRolledBack       = models.ConflictErr{Code: "BATCH_ROLLED_BACK"}
+	ErrBatchSkipped          = models.ConflictErr{Code: "BATCH_SKIPPED"}
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
 )
 
 type ListTaskOption struct {
-	WithDeleted bool
+	WithDeleted  bool
+	Limit        int
+	Cursor       string
+	Status       models.TaskStatus
+	Priority     *int
+	DueAfter     time.Time
+	DueBefore    time.Time
+	ParentID     *uuid.UUID
+	ListID       *uuid.UUID
+	Labels       []string
+	AllLabels    bool
+	NameContains string
+	Search       string
+	// Sort is one of the keys of sortColumns, prefixed with "-" for descending order
+	Sort string
 }
 
 type ListTaskOptionFunc func(*ListTaskOption)
@@ -23,10 +75,152 @@ func WithDeleted() ListTaskOptionFunc {
 	}
 }
 
+// WithLimit sets the page size, values out of (0, MaxListLimit] fall back to the nearest bound
+func WithLimit(limit int) ListTaskOptionFunc {
+	return func(to *ListTaskOption) {
+		to.Limit = limit
+	}
+}
+
+// WithCursor continues listing after the page which returned the cursor
+func WithCursor(cursor string) ListTaskOptionFunc {
+	return func(to *ListTaskOption) {
+		to.Cursor = cursor
+	}
+}
+
+func WithStatus(status models.TaskStatus) ListTaskOptionFunc {
+	return func(to *ListTaskOption) {
+		to.Status = status
+	}
+}
+
+func WithPriority(priority int) ListTaskOptionFunc {
+	return func(to *ListTaskOption) {
+		to.Priority = &priority
+	}
+}
+
+// WithDueAfter filters tasks due at or after t, tasks without due date are excluded
+func WithDueAfter(t time.Time) ListTaskOptionFunc {
+	return func(to *ListTaskOption) {
+		to.DueAfter = t
+	}
+}
+
+// WithDueBefore filters tasks due before t, tasks without due date are excluded
+func WithDueBefore(t time.Time) ListTaskOptionFunc {
+	return func(to *ListTaskOption) {
+		to.DueBefore = t
+	}
+}
+
+// WithParent filters the direct subtasks of the task
+func WithParent(id uuid.UUID) ListTaskOptionFunc {
+	return func(to *ListTaskOption) {
+		to.ParentID = &id
+	}
+}
+
+// WithList filters the tasks in the list
+func WithList(id uuid.UUID) ListTaskOptionFunc {
+	return func(to *ListTaskOption) {
+		to.ListID = &id
+	}
+}
+
+// WithLabels filters tasks having any of the labels, or all of them if matchAll is true
+func WithLabels(names []string, matchAll bool) ListTaskOptionFunc {
+	return func(to *ListTaskOption) {
+		to.Labels = names
+		to.AllLabels = matchAll
+	}
+}
+
+// WithNameContains filters tasks whose name contains the substring, case-insensitively
+func WithNameContains(name string) ListTaskOptionFunc {
+	return func(to *ListTaskOption) {
+		to.NameContains = name
+	}
+}
+
+// WithSearch filters tasks matching every word of the query as a prefix.
+// Tasks are ordered by relevance unless WithSort is given.
+func WithSearch(query string) ListTaskOptionFunc {
+	return func(to *ListTaskOption) {
+		to.Search = query
+	}
+}
+
+// WithSort orders tasks by position, created_at, updated_at, name, due_at, priority or rank, prefix
+// with "-" for descending. Tasks are ordered by position by default. Tasks without due date come last
+// in ascending due_at. rank is the relevance to the query of WithSearch.
+func WithSort(sort string) ListTaskOptionFunc {
+	return func(to *ListTaskOption) {
+		to.Sort = sort
+	}
+}
+
+// MutateTaskOption holds the preconditions of updating or deleting a task
+type MutateTaskOption struct {
+	Version *int
+}
+
+type MutateTaskOptionFunc func(*MutateTaskOption)
+
+// WithVersion applies the mutation only if the task is still at the version
+func WithVersion(version int) MutateTaskOptionFunc {
+	return func(mo *MutateTaskOption) {
+		mo.Version = &version
+	}
+}
+
+// BatchResult is the outcome of an operation in batch, Task is nil for deletion
+type BatchResult struct {
+	Task *models.Task
+	Err  error
+}
+
+// Task stores tasks. Creating, updating and deleting tasks write their task.created, task.updated and
+// task.deleted events to the outbox in the same transaction.
 type Task interface {
-	Create(ctx context.Context, name string) (*models.Task, error)
+	Create(ctx context.Context, params *models.CreateTaskParams) (*models.Task, error)
 	Get(ctx context.Context, id string) (*models.Task, error)
-	List(ctx context.Context, opts ...ListTaskOptionFunc) ([]*models.Task, error)
-	Put(ctx context.Context, id string, params *models.PutTaskParams) (*models.Task, error)
-	Delete(ctx context.Context, id string) error
+	// List returns a page of tasks and the cursor of the next page, which is empty on the last page
+	List(ctx context.Context, opts ...ListTaskOptionFunc) ([]*models.Task, string, error)
+	// Put replaces the task with params as a whole, the omitted fields are reset to their defaults
+	// rather than kept, use Patch to update some of them. The status must be reachable from the current one.
+	// Put, Patch and Delete return ErrTaskDeleted if the task is soft-deleted.
+	Put(ctx context.Context, id string, params *models.PutTaskParams, opts ...MutateTaskOptionFunc) (*models.Task, error)
+	// Patch updates only the non-nil fields of params
+	Patch(ctx context.Context, id string, params *models.PatchTaskParams, opts ...MutateTaskOptionFunc) (*models.Task, error)
+	Delete(ctx context.Context, id string, opts ...MutateTaskOptionFunc) error
+	// Move places the task between its new neighbors in the order of position
+	Move(ctx context.Context, id string, params *models.MoveTaskParams, opts ...MutateTaskOptionFunc) (*models.Task, error)
+	// Recur makes the next occurrence of at most limit recurring tasks which are done or due by now,
+	// and returns how many of them are handled. Tasks handled by another caller at the same time are skipped.
+	Recur(ctx context.Context, now time.Time, limit int) (int, error)
+	// Restore undoes the soft deletion of the task
+	Restore(ctx context.Context, id string, opts ...MutateTaskOptionFunc) (*models.Task, error)
+	// Purge removes the task permanently
+	Purge(ctx context.Context, id string, opts ...MutateTaskOptionFunc) error
+	// PurgeDeleted removes the tasks soft-deleted before the time permanently and returns how many are removed.
+	// Tasks having subtasks which are live or deleted after the time are kept until the subtasks can be purged.
+	// Positions grown long by moves between close neighbors are spread again afterwards.
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
+	// Batch applies the operations in a transaction and returns the result of each.
+	// In atomic mode the first failed operation rolls back the others, otherwise only itself.
+	// The operations run in a serializable transaction. The returned error is the failure of the transaction itself.
+	Batch(ctx context.Context, ops []*models.BatchTaskOperation, atomic bool) ([]*BatchResult, error)
+	// AddBlocker marks the task as blocked by the blocker, the task can't be done until the blocker is
+	// done, archived or deleted. Adding an existing edge is a no-op, an edge forming a cycle is rejected.
+	AddBlocker(ctx context.Context, id, blockerID string) error
+	RemoveBlocker(ctx context.Context, id, blockerID string) error
+	// ListBlockers returns the tasks directly blocking the task, deleted ones are left out
+	ListBlockers(ctx context.Context, id string) ([]*models.Task, error)
+	// WithTx runs fn with a store bound to a transaction, which is committed if fn returns nil
+	// and rolled back otherwise. The whole transaction is retried on serialization failures and
+	// deadlocks, so fn must be safe to run again. Calling WithTx on a bound store joins its transaction,
+	// ErrTxIsolation is returned if the transaction is less isolated than requested.
+	WithTx(ctx context.Context, fn func(Task) error, opts ...TxOptionFunc) error
 }
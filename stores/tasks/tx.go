@@ -0,0 +1,111 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultTxMaxRetries = 3
+	txRetryBackoff      = 20 * time.Millisecond
+
+	uniqueViolation    pq.ErrorCode = "23505"
+	positionConstraint              = "tasks_position_idx"
+)
+
+// ErrTxIsolation is returned when a transaction joined is less isolated than requested
+var ErrTxIsolation = errors.New("transaction joined is less isolated than requested")
+
+var retryableErrorCodes = map[pq.ErrorCode]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
+type TxOption struct {
+	Isolation  sql.IsolationLevel
+	MaxRetries int
+}
+
+type TxOptionFunc func(*TxOption)
+
+// WithIsolation sets the isolation level of the transaction, defaults to the database's
+func WithIsolation(level sql.IsolationLevel) TxOptionFunc {
+	return func(to *TxOption) {
+		to.Isolation = level
+	}
+}
+
+// WithMaxRetries sets how many times the transaction is retried on serialization failures and deadlocks
+func WithMaxRetries(n int) TxOptionFunc {
+	return func(to *TxOption) {
+		to.MaxRetries = n
+	}
+}
+
+func (im *impl) WithTx(ctx context.Context, fn func(Task) error, opts ...TxOptionFunc) error {
+	opt := TxOption{
+		MaxRetries: defaultTxMaxRetries,
+	}
+	for _, f := range opts {
+		f(&opt)
+	}
+
+	// NOTE: joins the transaction the store is already bound to, the outermost one commits and retries
+	if im.tx != nil {
+		if isolationRank(opt.Isolation) > isolationRank(im.isolation) {
+			return ErrTxIsolation
+		}
+		return fn(im)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := im.runTx(ctx, fn, &opt)
+		if err == nil || !retryable(err) || attempt >= opt.MaxRetries {
+			return err
+		}
+
+		zerolog.Ctx(ctx).Warn().Err(err).Int("attempt", attempt+1).Msg("transaction retried")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt+1) * txRetryBackoff):
+		}
+	}
+}
+
+func (im *impl) runTx(ctx context.Context, fn func(Task) error, opt *TxOption) error {
+	tx, err := im.db.BeginTxx(ctx, &sql.TxOptions{Isolation: opt.Isolation})
+	if err != nil {
+		return err
+	}
+	// NOTE: rollback is a no-op once the transaction is committed
+	defer tx.Rollback()
+
+	if err := fn(&impl{db: im.db, q: im.q.Bind(tx), tx: tx, isolation: opt.Isolation}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// isolationRank orders the isolation levels, the default one of Postgres is read committed
+func isolationRank(level sql.IsolationLevel) sql.IsolationLevel {
+	if level == sql.LevelDefault {
+		return sql.LevelReadCommitted
+	}
+	return level
+}
+
+// retryable tells whether the transaction may succeed if it's retried.
+// A duplicate position means the snapshot of the transaction predates the position taken by another one.
+func retryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return retryableErrorCodes[pqErr.Code] || (pqErr.Code == uniqueViolation && pqErr.Constraint == positionConstraint)
+}
@@ -0,0 +1,252 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/services/postgres"
+)
+
+const (
+	webhookColumns  = "pk, id, url, events, secret, created_at, updated_at"
+	deliveryColumns = "d.pk, d.id, d.webhook_id, d.event_id, o.event, d.attempts, d.next_attempt_at, d.delivered_at, " +
+		"d.dead_at, d.last_error, d.created_at"
+	// secretSize is how many random bytes a secret has
+	secretSize = 32
+	// pruneBatchSize is how many events are pruned at once
+	pruneBatchSize = 1000
+	// pendingConds are where the deliveries neither delivered nor dead are
+	pendingConds = "d.delivered_at IS NULL AND d.dead_at IS NULL"
+)
+
+var (
+	timeNow = time.Now
+
+	// claims finishes the claimed deliveries
+	claims = postgres.Lease{Table: "webhook_deliveries", DoneColumn: "delivered_at", FailedColumn: "dead_at", ErrLost: ErrClaimLost}
+)
+
+type impl struct {
+	q *postgres.Querier
+}
+
+func New(db *sqlx.DB, opts ...postgres.QueryOptionFunc) Webhook {
+	return &impl{
+		q: postgres.NewQuerier(db, opts...),
+	}
+}
+
+// eventsArray converts the events to their column value, nil means every event
+func eventsArray(events []string) pq.StringArray {
+	if events == nil {
+		return pq.StringArray{}
+	}
+	return pq.StringArray(events)
+}
+
+// newSecret returns a random secret to sign the deliveries of a webhook
+func newSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (im *impl) Create(ctx context.Context, params *models.CreateWebhookParams) (*models.Webhook, error) {
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	s := "INSERT INTO webhooks (id, url, events, secret, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $5) RETURNING " + webhookColumns
+	webhook := &models.Webhook{}
+	if err := im.q.Get(ctx, webhook, s, uuid.New(), params.URL, eventsArray(params.Events), secret, timeNow().UTC()); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("im.q.Get failed")
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (im *impl) Get(ctx context.Context, id string) (*models.Webhook, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	s := "SELECT " + webhookColumns + " FROM webhooks WHERE id=$1"
+	webhook := &models.Webhook{}
+	if err := im.q.Get(ctx, webhook, s, parsedID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (im *impl) List(ctx context.Context) ([]*models.Webhook, error) {
+	webhooks := []*models.Webhook{}
+	if err := im.q.Select(ctx, &webhooks, "SELECT "+webhookColumns+" FROM webhooks ORDER BY pk"); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (im *impl) Put(ctx context.Context, id string, params *models.PutWebhookParams) (*models.Webhook, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+
+	s := "UPDATE webhooks SET url=$1, events=$2, updated_at=$3 WHERE id=$4 RETURNING " + webhookColumns
+	webhook := &models.Webhook{}
+	if err := im.q.Get(ctx, webhook, s, params.URL, eventsArray(params.Events), timeNow().UTC(), parsedID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (im *impl) Delete(ctx context.Context, id string) error {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return ErrInvalidID
+	}
+
+	res, err := im.q.Exec(ctx, "DELETE FROM webhooks WHERE id=$1", parsedID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (im *impl) ListDeliveries(ctx context.Context, id string, params *models.ListWebhookDeliveryParams) ([]*models.WebhookDelivery, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidID
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = DefaultListDeliveryLimit
+	}
+	if limit > MaxListDeliveryLimit {
+		limit = MaxListDeliveryLimit
+	}
+
+	exists := false
+	if err := im.q.Get(ctx, &exists, "SELECT EXISTS (SELECT 1 FROM webhooks WHERE id=$1)", parsedID); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrWebhookNotFound
+	}
+
+	s := "SELECT " + deliveryColumns + " FROM webhook_deliveries d JOIN outbox o ON o.id=d.event_id WHERE d.webhook_id=$1"
+	switch params.Status {
+	case models.WebhookDeliveryStatusPending:
+		s += " AND " + pendingConds
+	case models.WebhookDeliveryStatusDelivered:
+		s += " AND d.delivered_at IS NOT NULL"
+	case models.WebhookDeliveryStatusDead:
+		s += " AND d.dead_at IS NOT NULL"
+	}
+	s += " ORDER BY d.pk DESC LIMIT $2"
+
+	deliveries := []*models.WebhookDelivery{}
+	if err := im.q.Select(ctx, &deliveries, s, parsedID, limit); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (im *impl) Dispatch(ctx context.Context, now time.Time, limit int) (int, error) {
+	// NOTE: an event nobody subscribes to is dispatched to no delivery, webhooks created afterwards don't get it
+	s := "WITH pending AS (\n" +
+		"SELECT pk, id, event FROM outbox WHERE dispatched_at IS NULL ORDER BY pk LIMIT $2 FOR UPDATE SKIP LOCKED),\n" +
+		"dispatched AS (\n" +
+		"INSERT INTO webhook_deliveries (webhook_id, event_id, next_attempt_at, created_at)\n" +
+		"SELECT w.id, p.id, $1, $1 FROM pending p JOIN webhooks w ON cardinality(w.events)=0 OR p.event=ANY(w.events)\n" +
+		"ORDER BY p.pk, w.pk ON CONFLICT (webhook_id, event_id) DO NOTHING)\n" +
+		"UPDATE outbox SET dispatched_at=$1 FROM pending WHERE outbox.pk=pending.pk"
+	res, err := im.q.Exec(ctx, s, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+func (im *impl) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.DueWebhookDelivery, error) {
+	// NOTE: the claim is committed at once instead of holding the rows locked while the deliveries are sent
+	s := "WITH due AS (\n" +
+		"SELECT d.pk FROM webhook_deliveries d WHERE " + pendingConds + " AND d.next_attempt_at <= $1\n" +
+		"ORDER BY d.pk LIMIT $3 FOR UPDATE SKIP LOCKED)\n" +
+		"UPDATE webhook_deliveries d SET attempts=d.attempts+1, next_attempt_at=$2 FROM due, webhooks w, outbox o\n" +
+		"WHERE d.pk=due.pk AND w.id=d.webhook_id AND o.id=d.event_id\n" +
+		"RETURNING " + deliveryColumns + ", w.url AS url, w.secret AS secret, o.payload AS payload, o.created_at AS event_created_at"
+	deliveries := []*models.DueWebhookDelivery{}
+	if err := im.q.Select(ctx, &deliveries, s, now, now.Add(lease), limit); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (im *impl) MarkDelivered(ctx context.Context, id uuid.UUID, attempts int, now time.Time) error {
+	return claims.MarkDone(ctx, im.q, id, attempts, now)
+}
+
+func (im *impl) MarkFailed(ctx context.Context, id uuid.UUID, attempts int, reason string, now, retryAt time.Time) error {
+	return claims.MarkFailed(ctx, im.q, id, attempts, reason, now, retryAt)
+}
+
+func (im *impl) Prune(ctx context.Context, before time.Time) (int, error) {
+	// NOTE: prune in batches so that a large backlog doesn't hold locks on too many rows at once,
+	// the deliveries are removed along with their events by the foreign key. Events not dispatched yet
+	// are kept however old they are, so that an outage of the dispatcher loses no event.
+	s := "DELETE FROM outbox WHERE pk IN (SELECT o.pk FROM outbox o WHERE o.created_at < $1 AND o.dispatched_at IS NOT NULL\n" +
+		"AND NOT EXISTS (\n" +
+		"SELECT 1 FROM webhook_deliveries d WHERE d.event_id=o.id AND " + pendingConds + ")\n" +
+		"LIMIT $2)"
+	total := 0
+	for {
+		res, err := im.q.Exec(ctx, s, before, pruneBatchSize)
+		if err != nil {
+			return total, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += int(n)
+		if n < pruneBatchSize {
+			return total, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+}
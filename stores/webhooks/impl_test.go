@@ -0,0 +1,199 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+	"github.com/chihkaiyu/task-todo-api/stores/storetest"
+)
+
+var (
+	mockCTX  = context.Background()
+	mockNow  = time.Now().UTC().Truncate(time.Microsecond)
+	mockUUID = uuid.New()
+)
+
+type webhookSuite struct {
+	storetest.Suite
+	webhookStore *impl
+}
+
+func TestWebhookSuite(t *testing.T) {
+	suite.Run(t, new(webhookSuite))
+}
+
+func (s *webhookSuite) SetupTest() {
+	s.Suite.SetupTest()
+	s.webhookStore = New(s.DB).(*impl)
+
+	// mock functions
+	timeNow = func() time.Time { return mockNow }
+}
+
+func (s *webhookSuite) createWebhook(events ...string) uuid.UUID {
+	id := uuid.New()
+	_, err := s.DB.Exec("INSERT INTO webhooks (id, url, events, secret) VALUES ($1, 'http://localhost/hook', $2, 'mock-secret')", id, pq.StringArray(events))
+	s.Require().NoError(err)
+	return id
+}
+
+func (s *webhookSuite) createEvent(event string) uuid.UUID {
+	id := uuid.New()
+	_, err := s.DB.Exec("INSERT INTO outbox (id, event, task_id, payload, created_at) VALUES ($1, $2, $3, '{}', $4)",
+		id, event, mockUUID, mockNow)
+	s.Require().NoError(err)
+	return id
+}
+
+func (s *webhookSuite) TestCreate() {
+	webhook, err := s.webhookStore.Create(mockCTX, &models.CreateWebhookParams{URL: "http://localhost/hook"})
+	s.Require().NoError(err)
+	s.Require().Equal("http://localhost/hook", webhook.URL)
+	s.Require().Equal(pq.StringArray{}, webhook.Events)
+	s.Require().Equal(mockNow, webhook.CreatedAt.UTC())
+	s.Require().Len(webhook.Secret, 64)
+
+	got, err := s.webhookStore.Get(mockCTX, webhook.ID.String())
+	s.Require().NoError(err)
+	s.Require().Equal(webhook.ID, got.ID)
+
+	// every webhook has a secret of its own
+	other, err := s.webhookStore.Create(mockCTX, &models.CreateWebhookParams{URL: "http://localhost/hook"})
+	s.Require().NoError(err)
+	s.Require().NotEqual(webhook.Secret, other.Secret)
+
+	_, err = s.webhookStore.Get(mockCTX, uuid.New().String())
+	s.Require().EqualError(err, ErrWebhookNotFound.Error())
+	_, err = s.webhookStore.Get(mockCTX, "mock-id")
+	s.Require().EqualError(err, ErrInvalidID.Error())
+}
+
+func (s *webhookSuite) TestPut() {
+	id := s.createWebhook()
+
+	webhook, err := s.webhookStore.Put(mockCTX, id.String(), &models.PutWebhookParams{
+		URL:    "https://localhost/hook",
+		Events: []string{models.TaskEventDeleted},
+	})
+	s.Require().NoError(err)
+	s.Require().Equal("https://localhost/hook", webhook.URL)
+	s.Require().Equal(pq.StringArray{models.TaskEventDeleted}, webhook.Events)
+	s.Require().Equal(mockNow, webhook.UpdatedAt.UTC())
+
+	_, err = s.webhookStore.Put(mockCTX, uuid.New().String(), &models.PutWebhookParams{URL: "https://localhost/hook"})
+	s.Require().EqualError(err, ErrWebhookNotFound.Error())
+}
+
+func (s *webhookSuite) TestDelete() {
+	id := s.createWebhook()
+	s.createEvent(models.TaskEventCreated)
+	_, err := s.webhookStore.Dispatch(mockCTX, mockNow, 10)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.webhookStore.Delete(mockCTX, id.String()))
+	s.Require().EqualError(s.webhookStore.Delete(mockCTX, id.String()), ErrWebhookNotFound.Error())
+	webhooks, err := s.webhookStore.List(mockCTX)
+	s.Require().NoError(err)
+	s.Require().Empty(webhooks)
+	n := 0
+	s.Require().NoError(s.DB.Get(&n, "SELECT COUNT(*) FROM webhook_deliveries"))
+	s.Require().Zero(n)
+}
+
+func (s *webhookSuite) TestDeliver() {
+	all := s.createWebhook()
+	deleted := s.createWebhook(models.TaskEventDeleted)
+	created := s.createEvent(models.TaskEventCreated)
+	s.createEvent(models.TaskEventDeleted)
+
+	n, err := s.webhookStore.Dispatch(mockCTX, mockNow, 10)
+	s.Require().NoError(err)
+	s.Require().Equal(2, n)
+	// NOTE: an event is dispatched once
+	n, err = s.webhookStore.Dispatch(mockCTX, mockNow, 10)
+	s.Require().NoError(err)
+	s.Require().Zero(n)
+
+	due, err := s.webhookStore.Claim(mockCTX, mockNow, time.Minute, 10)
+	s.Require().NoError(err)
+	s.Require().Len(due, 3)
+	s.Require().Equal(all, due[0].WebhookID)
+	s.Require().Equal(created, due[0].EventID)
+	s.Require().Equal(models.TaskEventCreated, due[0].Event)
+	s.Require().Equal("http://localhost/hook", due[0].URL)
+	s.Require().Equal("mock-secret", due[0].Secret)
+	s.Require().Equal(1, due[0].Attempts)
+	s.Require().True(mockNow.Equal(due[0].WebhookEvent().CreatedAt))
+	s.Require().Equal(models.TaskEventDeleted, due[2].Event)
+	s.Require().Equal(deleted, due[2].WebhookID)
+
+	// claimed deliveries are left out until the lease passes
+	due2, err := s.webhookStore.Claim(mockCTX, mockNow, time.Minute, 10)
+	s.Require().NoError(err)
+	s.Require().Empty(due2)
+
+	s.Require().NoError(s.webhookStore.MarkDelivered(mockCTX, due[0].ID, 1, mockNow))
+	s.Require().EqualError(s.webhookStore.MarkDelivered(mockCTX, due[0].ID, 1, mockNow), ErrClaimLost.Error())
+	s.Require().NoError(s.webhookStore.MarkFailed(mockCTX, due[1].ID, 1, "mock-error", mockNow, mockNow.Add(time.Second)))
+	s.Require().NoError(s.webhookStore.MarkFailed(mockCTX, due[2].ID, 1, "mock-error", mockNow, time.Time{}))
+	due2, err = s.webhookStore.Claim(mockCTX, mockNow.Add(time.Second), time.Minute, 10)
+	s.Require().NoError(err)
+	s.Require().Len(due2, 1)
+	s.Require().Equal(due[1].ID, due2[0].ID)
+	s.Require().Equal(2, due2[0].Attempts)
+	s.Require().Equal("mock-error", due2[0].LastError.String)
+	// the delivery claimed again can't be finished with the old claim
+	s.Require().EqualError(s.webhookStore.MarkDelivered(mockCTX, due[1].ID, 1, mockNow), ErrClaimLost.Error())
+
+	deliveries, err := s.webhookStore.ListDeliveries(mockCTX, all.String(), &models.ListWebhookDeliveryParams{})
+	s.Require().NoError(err)
+	s.Require().Len(deliveries, 2)
+	s.Require().Equal(due[1].ID, deliveries[0].ID)
+	s.Require().Equal(models.WebhookDeliveryStatusPending, deliveries[0].Status())
+	s.Require().Equal(models.WebhookDeliveryStatusDelivered, deliveries[1].Status())
+
+	deliveries, err = s.webhookStore.ListDeliveries(mockCTX, deleted.String(), &models.ListWebhookDeliveryParams{
+		Status: models.WebhookDeliveryStatusDead,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(deliveries, 1)
+	s.Require().Equal(models.WebhookDeliveryStatusDead, deliveries[0].Status())
+
+	_, err = s.webhookStore.ListDeliveries(mockCTX, uuid.New().String(), &models.ListWebhookDeliveryParams{})
+	s.Require().EqualError(err, ErrWebhookNotFound.Error())
+}
+
+func (s *webhookSuite) TestPrune() {
+	s.createWebhook()
+	delivered := s.createEvent(models.TaskEventCreated)
+	pending := s.createEvent(models.TaskEventUpdated)
+	_, err := s.webhookStore.Dispatch(mockCTX, mockNow, 10)
+	s.Require().NoError(err)
+	due, err := s.webhookStore.Claim(mockCTX, mockNow, time.Minute, 10)
+	s.Require().NoError(err)
+	s.Require().Len(due, 2)
+	s.Require().Equal(delivered, due[0].EventID)
+	s.Require().NoError(s.webhookStore.MarkDelivered(mockCTX, due[0].ID, 1, mockNow))
+	undispatched := s.createEvent(models.TaskEventDeleted)
+
+	n, err := s.webhookStore.Prune(mockCTX, mockNow.Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Require().Zero(n)
+
+	// the event still being delivered and the one not dispatched yet are kept
+	n, err = s.webhookStore.Prune(mockCTX, mockNow.Add(time.Hour))
+	s.Require().NoError(err)
+	s.Require().Equal(1, n)
+	ids := []uuid.UUID{}
+	s.Require().NoError(s.DB.Select(&ids, "SELECT id FROM outbox ORDER BY pk"))
+	s.Require().Equal([]uuid.UUID{pending, undispatched}, ids)
+	count := 0
+	s.Require().NoError(s.DB.Get(&count, "SELECT COUNT(*) FROM webhook_deliveries"))
+	s.Require().Equal(1, count)
+}
@@ -0,0 +1,54 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/chihkaiyu/task-todo-api/models"
+)
+
+var (
+	ErrWebhookNotFound = models.NotFoundErr{Code: "WEBHOOK_NOT_FOUND"}
+	ErrInvalidID       = models.BadRequestErr{Code: "INVALID_ID"}
+	// ErrClaimLost is returned when the delivery is claimed again after the lease passes, or finished by another caller
+	ErrClaimLost = models.ConflictErr{Code: "CLAIM_LOST"}
+)
+
+const (
+	DefaultListDeliveryLimit = 20
+	MaxListDeliveryLimit     = 100
+)
+
+type Webhook interface {
+	// Create adds a webhook with a random secret to sign its deliveries
+	Create(ctx context.Context, params *models.CreateWebhookParams) (*models.Webhook, error)
+	Get(ctx context.Context, id string) (*models.Webhook, error)
+	// List returns the webhooks in the order they're created
+	List(ctx context.Context) ([]*models.Webhook, error)
+	// Put replaces the url and events of the webhook, events dispatched already are sent to the old url
+	Put(ctx context.Context, id string, params *models.PutWebhookParams) (*models.Webhook, error)
+	// Delete removes the webhook along with its deliveries
+	Delete(ctx context.Context, id string) error
+	// ListDeliveries returns the latest deliveries of the webhook, of the status if it isn't empty
+	ListDeliveries(ctx context.Context, id string, params *models.ListWebhookDeliveryParams) ([]*models.WebhookDelivery, error)
+
+	// Dispatch makes the deliveries of at most limit events in the outbox to the webhooks subscribing them,
+	// and returns how many events are dispatched. Events dispatched by another caller at the same time are skipped.
+	Dispatch(ctx context.Context, now time.Time, limit int) (int, error)
+	// Claim takes at most limit pending deliveries which are due by now. Claimed deliveries can't be claimed
+	// again until lease passes, so that deliveries claimed by a worker which goes away are retried.
+	// Deliveries claimed by another caller at the same time are skipped.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*models.DueWebhookDelivery, error)
+	// MarkDelivered marks the claimed delivery delivered. attempts is the one of the claim, ErrClaimLost is
+	// returned if the delivery has been claimed again or finished since.
+	MarkDelivered(ctx context.Context, id uuid.UUID, attempts int, now time.Time) error
+	// MarkFailed records the failure of sending the claimed delivery, which is retried after retryAt
+	// or marked dead if retryAt is zero. The claim is checked like MarkDelivered.
+	MarkFailed(ctx context.Context, id uuid.UUID, attempts int, reason string, now, retryAt time.Time) error
+	// Prune removes the events in the outbox created before the time along with their deliveries, and returns
+	// how many events are removed. Events not dispatched yet, or having deliveries which are neither delivered
+	// nor dead, are kept.
+	Prune(ctx context.Context, before time.Time) (int, error)
+}